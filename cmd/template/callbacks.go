@@ -0,0 +1,203 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Callback is a callback declared on an operation. The embedded Handler
+// describes the request sent to the subscriber.
+type Callback struct {
+	Handler
+
+	// Parent is the handler of the operation registering the callback.
+	Parent Handler
+
+	// Event is the key of the callback on the operation.
+	Event string
+
+	// Expression is the runtime expression the callback URL is built from.
+	Expression string
+
+	// URLFormat and URLValues are the fmt.Sprintf format and arguments that
+	// build the callback URL from the arguments of the parent handler.
+	URLFormat string
+	URLValues []CallbackValue
+}
+
+// CallbackValue is a value of the parent request referenced by the runtime
+// expression of a callback.
+type CallbackValue struct {
+	// Expr is the Go expression of the value.
+	Expr string
+
+	// Pointer reports whether Expr is a pointer that has to be checked and
+	// dereferenced.
+	Pointer bool
+
+	// Query reports whether the value is part of the query string of the URL
+	// and has to be escaped.
+	Query bool
+
+	// Source is the runtime expression the value was resolved from.
+	Source string
+}
+
+// ParentArgs returns the arguments of the parent handler, without the context.
+func (c Callback) ParentArgs() (string, error) {
+	args, err := c.Parent.TypeList("go")
+	if err != nil {
+		return "", err
+	}
+	args = strings.TrimPrefix(args, "ctx context.Context")
+	return strings.TrimPrefix(args, ", "), nil
+}
+
+// ClientArgs returns the arguments of the method invoking the callback.
+func (c Callback) ClientArgs() (string, error) {
+	args, err := c.TypeList("go")
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(args, "ctx context.Context", "ctx context.Context, url string", 1), nil
+}
+
+func getCallbacks(parent Handler, op *v3high.Operation, opts cmdOptions) ([]Callback, error) {
+	if op.Callbacks == nil {
+		return nil, nil
+	}
+
+	var callbacks []Callback
+	for pair := op.Callbacks.First(); pair != nil; pair = pair.Next() {
+		event := pair.Key()
+		cb := pair.Value()
+		if cb.Expression.Len() != 1 {
+			return nil, fmt.Errorf("callback %s: exactly one expression is supported", event)
+		}
+
+		expr := cb.Expression.First()
+		ops := expr.Value().GetOperations()
+		if ops.Len() != 1 {
+			return nil, fmt.Errorf("callback %s: exactly one operation is supported", event)
+		}
+		cbOp := ops.First()
+
+		h, err := buildHandler(parent.Name+typeName(event), expr.Key(), cbOp.Key(), cbOp.Value(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("callback %s: %w", event, err)
+		}
+
+		for _, p := range h.Params {
+			if p.Location == "path" {
+				return nil, fmt.Errorf("callback %s: path parameters are not supported", event)
+			}
+		}
+		if h.ResponseType != "" && (h.ReturnsReader() || h.ResponseType == "[]byte") {
+			return nil, fmt.Errorf("callback %s: only JSON responses are supported", event)
+		}
+
+		callbacks = append(callbacks, Callback{
+			Handler:    h,
+			Parent:     parent,
+			Event:      event,
+			Expression: expr.Key(),
+		})
+	}
+
+	return callbacks, nil
+}
+
+var runtimeExpression = regexp.MustCompile(`\{(\$[^}]+)\}`)
+
+// buildCallbackURL resolves the runtime expression of a callback against the
+// arguments of its parent handler.
+func buildCallbackURL(c Callback, models Models) (string, []CallbackValue, error) {
+	var (
+		format strings.Builder
+		values []CallbackValue
+		last   int
+	)
+
+	for _, m := range runtimeExpression.FindAllStringSubmatchIndex(c.Expression, -1) {
+		format.WriteString(strings.ReplaceAll(c.Expression[last:m[0]], "%", "%%"))
+		format.WriteString("%v")
+		last = m[1]
+
+		v, err := resolveRuntimeExpression(c.Parent, c.Expression[m[2]:m[3]], models)
+		if err != nil {
+			return "", nil, err
+		}
+		v.Query = strings.Contains(c.Expression[:m[0]], "?")
+		values = append(values, v)
+	}
+	format.WriteString(strings.ReplaceAll(c.Expression[last:], "%", "%%"))
+
+	return format.String(), values, nil
+}
+
+func resolveRuntimeExpression(parent Handler, expr string, models Models) (CallbackValue, error) {
+	v := CallbackValue{Source: expr}
+
+	source, ok := strings.CutPrefix(expr, "$request.")
+	if !ok {
+		return v, fmt.Errorf("unsupported runtime expression %q", expr)
+	}
+
+	if pointer, ok := strings.CutPrefix(source, "body#/"); ok {
+		if parent.RequestBodyType == "" {
+			return v, fmt.Errorf("runtime expression %q: request has no body", expr)
+		}
+		if strings.Contains(pointer, "/") {
+			return v, fmt.Errorf("runtime expression %q: nested properties are not supported", expr)
+		}
+
+		model, ok := models.find(parent.RequestBodyType)
+		if !ok {
+			return v, fmt.Errorf("runtime expression %q: request body model %q not found", expr, parent.RequestBodyType)
+		}
+		field, ok := model.fieldByTag(pointer)
+		if !ok {
+			return v, fmt.Errorf("runtime expression %q: request body model %q has no property %q", expr, model.Name, pointer)
+		}
+
+		v.Expr = "req." + field.Name
+		v.Pointer = field.Pointer()
+		return v, nil
+	}
+
+	location, name, ok := strings.Cut(source, ".")
+	if !ok {
+		return v, fmt.Errorf("unsupported runtime expression %q", expr)
+	}
+
+	for _, p := range parent.Params {
+		if p.Location != location || p.Name != name {
+			continue
+		}
+
+		switch location {
+		case "path":
+			v.Expr = argName(p.Name)
+		case "query", "header":
+			v.Expr = "qp." + typeName(p.Name)
+			v.Pointer = !p.Required
+		}
+		return v, nil
+	}
+
+	return v, fmt.Errorf("runtime expression %q does not match a parameter of %s", expr, parent.Name)
+}
+
+// ParamHandlers returns the handlers of the operations and of the callbacks,
+// all of which have their parameters parsed from a request.
+func (t TemplateData) ParamHandlers() []Handler {
+	handlers := make([]Handler, 0, len(t.Handlers)+len(t.Callbacks))
+	handlers = append(handlers, t.Handlers...)
+	for _, c := range t.Callbacks {
+		handlers = append(handlers, c.Handler)
+	}
+	return handlers
+}
@@ -0,0 +1,76 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCallbackURL(t *testing.T) {
+	models := Models{
+		{
+			Name: "SubscribeRequest",
+			Fields: []Field{
+				{Name: "CallbackURL", Type: "string", StructTag: "callback_url", Required: true},
+				{Name: "VerifyURL", Type: "string", StructTag: "verify_url"},
+			},
+		},
+	}
+
+	parent := Handler{
+		Name:            "subscribe",
+		RequestBodyType: "SubscribeRequest",
+		Params: Params{
+			{Name: "id", Type: "string", Location: "path", Required: true},
+			{Name: "tenant", Type: "string", Location: "query"},
+			{Name: "X-Region", Type: "string", Location: "header", Required: true},
+		},
+	}
+
+	tests := []struct {
+		expression     string
+		expectedFormat string
+		expectedValues []CallbackValue
+		err            string
+	}{
+		{
+			"{$request.body#/callback_url}",
+			"%v",
+			[]CallbackValue{{Expr: "req.CallbackURL", Source: "$request.body#/callback_url"}},
+			"",
+		},
+		{
+			"{$request.body#/verify_url}/100%/{$request.path.id}?tenant={$request.query.tenant}",
+			"%v/100%%/%v?tenant=%v",
+			[]CallbackValue{
+				{Expr: "req.VerifyURL", Pointer: true, Source: "$request.body#/verify_url"},
+				{Expr: "id", Source: "$request.path.id"},
+				{Expr: "qp.Tenant", Pointer: true, Query: true, Source: "$request.query.tenant"},
+			},
+			"",
+		},
+		{
+			"https://{$request.header.X-Region}.example.com/hook",
+			"https://%v.example.com/hook",
+			[]CallbackValue{{Expr: "qp.XRegion", Source: "$request.header.X-Region"}},
+			"",
+		},
+		{"{$request.body#/nope}", "", nil, `has no property "nope"`},
+		{"{$request.body#/a/b}", "", nil, "nested properties are not supported"},
+		{"{$request.query.nope}", "", nil, "does not match a parameter"},
+		{"{$response.body#/url}", "", nil, "unsupported runtime expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			format, values, err := buildCallbackURL(Callback{Parent: parent, Expression: tt.expression}, models)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedFormat, format)
+			require.Equal(t, tt.expectedValues, values)
+		})
+	}
+}
@@ -1,24 +1,30 @@
 package template
 
 import (
-	"embed"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"path"
+	"os/signal"
+	"path/filepath"
 
 	version "github.com/jasonhancock/cobra-version"
 	"github.com/jasonhancock/cobraflags/root"
 	"github.com/jasonhancock/jasongen/internal/loader"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
 )
 
-//go:embed templates
-var templates embed.FS
-
 type cmdOptions struct {
-	overwrite bool
-	pkgModels string
-	language  string
+	overwrite    bool
+	pkgModels    string
+	language     string
+	templateDirs []string
+	check        bool
+	merge        bool
+	watch        bool
+	groupByTag   bool
+	filter       operationFilter
 }
 
 // NewCmd sets up the command.
@@ -38,7 +44,22 @@ func NewCmd(r *root.Command) *cobra.Command {
 				files   = args[3:]
 			)
 
-			return runTemplate(pkg, tmpl, outfile, opts, *r.Version, files...)
+			generate := func() error {
+				return runTemplate(pkg, tmpl, outfile, opts, *r.Version, files...)
+			}
+			if !opts.watch {
+				return generate()
+			}
+
+			in, err := watchInputs(tmpl, opts, files...)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			return watchTemplate(ctx, cmd.ErrOrStderr(), watchDebounce, in, generate)
 		},
 	}
 
@@ -63,6 +84,43 @@ func NewCmd(r *root.Command) *cobra.Command {
 		"The language of the generated file (go|js).",
 	)
 
+	cmd.Flags().StringArrayVar(
+		&opts.templateDirs,
+		"template-dir",
+		nil,
+		"A directory of templates and partials layered over the embedded templates. Can be repeated, later directories take precedence.",
+	)
+
+	cmd.Flags().BoolVar(
+		&opts.check,
+		"check",
+		false,
+		"When true, renders in memory and exits non-zero with a unified diff if the outfile is out of date. Nothing is written.",
+	)
+
+	cmd.Flags().BoolVar(
+		&opts.merge,
+		"merge",
+		false,
+		"When true, merges into an existing outfile instead of overwriting it: missing methods are appended and changed signatures are updated, leaving existing bodies untouched. Intended for templates you edit, like service.",
+	)
+
+	cmd.Flags().BoolVar(
+		&opts.watch,
+		"watch",
+		false,
+		"When true, keeps running and renders the template again whenever the spec files or templates change.",
+	)
+
+	cmd.Flags().BoolVar(
+		&opts.groupByTag,
+		"group-by-tag",
+		false,
+		"When true, generates one SVC interface per tag, grouping the operations by their first tag. Operations without tags stay in SVC.",
+	)
+
+	addFilterFlags(cmd, &opts.filter)
+
 	return cmd
 }
 
@@ -72,20 +130,55 @@ func runTemplate(pkg, tmpl, outfile string, opts cmdOptions, info version.Info,
 		return err
 	}
 
+	if opts.filter.active() {
+		if err := filterDocument(&result.Model, opts.filter); err != nil {
+			return err
+		}
+	}
+
 	td, err := templateDataFrom(result, pkg, info, opts)
 	if err != nil {
 		return err
 	}
 
-	var templateBytes string
-	if data, err := templates.ReadFile(path.Join("templates", tmpl+"."+opts.language+".tmpl")); err == nil {
-		templateBytes = string(data)
-	} else {
-		data, err := os.ReadFile(tmpl)
-		if err != nil {
+	t, err := parseTemplate(tmpl, opts)
+	if err != nil {
+		return err
+	}
+	td.GeneratorInfo.Fingerprint = newFingerprint(tmpl, opts.language, td.Spec, t)
+
+	if opts.merge && opts.language != "go" {
+		return fmt.Errorf("merging is only supported for go, not %s", opts.language)
+	}
+
+	if opts.check || opts.merge {
+		var buf bytes.Buffer
+		if err := renderTemplate(t, td, &buf, opts.language); err != nil {
 			return err
 		}
-		templateBytes = string(data)
+		content := buf.Bytes()
+
+		if opts.merge {
+			existing, err := os.ReadFile(outfile)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err == nil {
+				var report mergeReport
+				content, report, err = mergeGoFile(existing, content)
+				if err != nil {
+					return fmt.Errorf("merging into %s: %w", outfile, err)
+				}
+				if err := report.write(os.Stdout, outfile); err != nil {
+					return err
+				}
+			}
+		}
+
+		if opts.check {
+			return checkFile(os.Stdout, outfile, content)
+		}
+		return os.WriteFile(outfile, content, 0644)
 	}
 
 	{ // Determine if we need to write the original file or not.
@@ -107,5 +200,36 @@ func runTemplate(pkg, tmpl, outfile string, opts cmdOptions, info version.Info,
 	}
 	defer fh.Close()
 
-	return renderTemplate(templateBytes, td, fh, opts.pkgModels, opts.language)
+	return renderTemplate(t, td, fh, opts.language)
+}
+
+// checkFile compares the contents of a file against the expected contents,
+// writing a unified diff to out if they differ. A missing file is treated as
+// empty.
+func checkFile(out io.Writer, file string, expected []byte) error {
+	current, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if bytes.Equal(current, expected) {
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(current)),
+		B:        difflib.SplitLines(string(expected)),
+		FromFile: "a/" + filepath.ToSlash(file),
+		ToFile:   "b/" + filepath.ToSlash(file),
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("computing diff of %s: %w", file, err)
+	}
+
+	if _, err := io.WriteString(out, diff); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%s is out of date", file)
 }
@@ -1,6 +1,7 @@
 package template
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"os"
@@ -26,6 +27,15 @@ func TestRunTemplate(t *testing.T) {
 	for _, caseName := range cases {
 		caseName = filepath.Base(caseName)
 		file := filepath.Join("testdata", "cases", caseName, "openapi.yaml")
+		files := []string{"testdata/openapi_base.yaml", file}
+
+		// A case declaring its own version, e.g. to test 3.0, isn't merged
+		// into the 3.1 base document.
+		b, err := os.ReadFile(file)
+		require.NoError(t, err)
+		if bytes.HasPrefix(b, []byte("openapi:")) {
+			files = files[1:]
+		}
 
 		t.Run(caseName, func(t *testing.T) {
 			for _, tmpl := range templates {
@@ -41,10 +51,12 @@ func TestRunTemplate(t *testing.T) {
 				t.Run(fmt.Sprintf("%s.%s", tmpl, lang), func(t *testing.T) {
 					tests := []struct {
 						models      string
+						groupByTag  bool
 						expectedDir string
 					}{
-						{"", "expected"},
-						{"github.com/example/somemodels", "expected_models"},
+						{"", false, "expected"},
+						{"github.com/example/somemodels", false, "expected_models"},
+						{"", true, "expected_group_by_tag"},
 					}
 
 					for _, tt := range tests {
@@ -53,12 +65,16 @@ func TestRunTemplate(t *testing.T) {
 							// takes forever, so just run it against the "all" test suite.
 							continue
 						}
+						if caseName != "tags" && tt.groupByTag {
+							continue
+						}
 						dir := t.TempDir()
-						t.Run("models("+tt.models+")", func(t *testing.T) {
+						t.Run(fmt.Sprintf("models(%s),group_by_tag(%t)", tt.models, tt.groupByTag), func(t *testing.T) {
 							opts := cmdOptions{
-								overwrite: false,
-								pkgModels: tt.models,
-								language:  lang,
+								overwrite:  false,
+								pkgModels:  tt.models,
+								language:   lang,
+								groupByTag: tt.groupByTag,
 							}
 							outfile := filepath.Join(dir, tmpl+".go")
 							err := runTemplate(
@@ -67,8 +83,7 @@ func TestRunTemplate(t *testing.T) {
 								outfile,
 								opts,
 								version.Info{Version: "1.2.3"},
-								"testdata/openapi_base.yaml",
-								file,
+								files...,
 							)
 							if err != nil {
 								if _, err := os.Stat(outfile); err == nil {
@@ -95,3 +110,45 @@ func TestRunTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestRunTemplateCheck(t *testing.T) {
+	files := []string{"testdata/openapi_base.yaml", "testdata/cases/all/openapi.yaml"}
+	info := version.Info{Version: "1.2.3"}
+	outfile := filepath.Join(t.TempDir(), "models.go")
+
+	opts := cmdOptions{overwrite: true, language: "go", check: true}
+
+	// The outfile doesn't exist yet.
+	require.ErrorContains(t, runTemplate("widgets", "models", outfile, opts, info, files...), "is out of date")
+	_, err := os.Stat(outfile)
+	require.True(t, os.IsNotExist(err))
+
+	opts.check = false
+	require.NoError(t, runTemplate("widgets", "models", outfile, opts, info, files...))
+
+	opts.check = true
+	require.NoError(t, runTemplate("widgets", "models", outfile, opts, info, files...))
+
+	modified := []byte("// edited by hand\n")
+	require.NoError(t, os.WriteFile(outfile, modified, 0644))
+	require.ErrorContains(t, runTemplate("widgets", "models", outfile, opts, info, files...), "is out of date")
+
+	b, err := os.ReadFile(outfile)
+	require.NoError(t, err)
+	require.Equal(t, modified, b)
+}
+
+func TestCheckFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(file, []byte("a\nb\nc\n"), 0644))
+
+	var buf strings.Builder
+	require.NoError(t, checkFile(&buf, file, []byte("a\nb\nc\n")))
+	require.Empty(t, buf.String())
+
+	err := checkFile(&buf, file, []byte("a\nB\nc\n"))
+	require.EqualError(t, err, file+" is out of date")
+	require.Contains(t, buf.String(), "--- a/"+filepath.ToSlash(file))
+	require.Contains(t, buf.String(), "+++ b/"+filepath.ToSlash(file))
+	require.Contains(t, buf.String(), "-b\n+B\n")
+}
@@ -0,0 +1,66 @@
+package template
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+const extensionSunset = "x-sunset"
+
+// getDeprecation returns whether the operation is deprecated and the date it
+// will be removed on, from x-sunset. An operation with a sunset date is
+// deprecated, whether or not it's marked as such.
+func getDeprecation(op *v3high.Operation) (bool, time.Time, error) {
+	str, err := getExtensionString(op.Extensions, extensionSunset)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("%s: %w", extensionSunset, err)
+	}
+
+	var sunset time.Time
+	if str != "" {
+		sunset, err = time.Parse(time.RFC3339, str)
+		if err != nil {
+			sunset, err = time.Parse(time.DateOnly, str)
+		}
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("%s: %q is neither a date nor a RFC 3339 timestamp", extensionSunset, str)
+		}
+	}
+
+	return fromBoolPtr(op.Deprecated) || !sunset.IsZero(), sunset, nil
+}
+
+// DeprecationComment returns the Deprecated: paragraph of the doc comments of
+// the methods generated for the handler, if it's deprecated.
+func (h Handler) DeprecationComment() string {
+	if !h.Deprecated {
+		return ""
+	}
+	if h.Sunset.IsZero() {
+		return fmt.Sprintf("Deprecated: %s is deprecated.", h.ExportedName())
+	}
+	return fmt.Sprintf("Deprecated: %s is deprecated and will be removed on %s.", h.ExportedName(), h.Sunset.UTC().Format(time.DateOnly))
+}
+
+// SunsetLiteral returns the value of the Sunset header of the handler as a Go
+// string literal.
+func (h Handler) SunsetLiteral() string {
+	if h.Sunset.IsZero() {
+		return `""`
+	}
+	return strconv.Quote(h.Sunset.UTC().Format(http.TimeFormat))
+}
+
+// HasDeprecated reports whether any handler is deprecated.
+func (t TemplateData) HasDeprecated() bool {
+	for _, h := range t.Handlers {
+		if h.Deprecated {
+			return true
+		}
+	}
+	return false
+}
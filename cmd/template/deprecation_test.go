@@ -0,0 +1,69 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestGetDeprecation(t *testing.T) {
+	yes := true
+	sunset := func(v string) *orderedmap.Map[string, *yaml.Node] {
+		m := orderedmap.New[string, *yaml.Node]()
+		m.Set(extensionSunset, &yaml.Node{Kind: yaml.ScalarNode, Value: v})
+		return m
+	}
+
+	tests := []struct {
+		desc       string
+		op         *v3high.Operation
+		deprecated bool
+		sunset     time.Time
+		comment    string
+		literal    string
+		err        string
+	}{
+		{"none", &v3high.Operation{Extensions: orderedmap.New[string, *yaml.Node]()}, false, time.Time{}, "", `""`, ""},
+		{"deprecated", &v3high.Operation{Deprecated: &yes, Extensions: orderedmap.New[string, *yaml.Node]()}, true, time.Time{}, "Deprecated: WidgetsGet is deprecated.", `""`, ""},
+		{
+			"date",
+			&v3high.Operation{Extensions: sunset("2026-12-31")},
+			true,
+			time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+			"Deprecated: WidgetsGet is deprecated and will be removed on 2026-12-31.",
+			`"Thu, 31 Dec 2026 00:00:00 GMT"`,
+			"",
+		},
+		{
+			"timestamp",
+			&v3high.Operation{Deprecated: &yes, Extensions: sunset("2026-12-31T18:00:00-08:00")},
+			true,
+			time.Date(2027, 1, 1, 2, 0, 0, 0, time.UTC),
+			"Deprecated: WidgetsGet is deprecated and will be removed on 2027-01-01.",
+			`"Fri, 01 Jan 2027 02:00:00 GMT"`,
+			"",
+		},
+		{"invalid", &v3high.Operation{Extensions: sunset("soon")}, false, time.Time{}, "", "", `x-sunset: "soon" is neither a date nor a RFC 3339 timestamp`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			deprecated, sunset, err := getDeprecation(tt.op)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.deprecated, deprecated)
+			require.True(t, tt.sunset.Equal(sunset))
+
+			h := Handler{Name: "widgetsGet", Deprecated: deprecated, Sunset: sunset}
+			require.Equal(t, tt.comment, h.DeprecationComment())
+			require.Equal(t, tt.literal, h.SunsetLiteral())
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// setFieldDoc sets the documentation of a field from the schema of its
+// property. Referenced schemas are documented on their own type, so only the
+// deprecation of the property is taken from them.
+func setFieldDoc(f *Field, sp *base.SchemaProxy) error {
+	s := sp.Schema()
+	if s == nil {
+		return nil
+	}
+	f.Deprecated = fromBoolPtr(s.Deprecated)
+	if sp.IsReference() {
+		return nil
+	}
+
+	f.Description = s.Description
+	f.Format = s.Format
+	f.Constraints = schemaConstraints(s)
+
+	if s.Example != nil {
+		example, err := exampleLiteral(s.Example)
+		if err != nil {
+			return fmt.Errorf("example: %w", err)
+		}
+		f.Example = example
+	}
+
+	return nil
+}
+
+// schemaConstraints describes the validation keywords of a schema.
+func schemaConstraints(s *base.Schema) []string {
+	var constraints []string
+	addFloat := func(name string, v *float64) {
+		if v != nil {
+			constraints = append(constraints, name+" "+strconv.FormatFloat(*v, 'g', -1, 64))
+		}
+	}
+	addInt := func(name string, v *int64) {
+		if v != nil {
+			constraints = append(constraints, name+" "+strconv.FormatInt(*v, 10))
+		}
+	}
+
+	// In 3.0, exclusiveMinimum and exclusiveMaximum are booleans modifying
+	// minimum and maximum. In 3.1, they're numbers.
+	minimum, maximum := "minimum", "maximum"
+	if v := s.ExclusiveMinimum; v != nil {
+		if v.IsA() && v.A {
+			minimum = "exclusive minimum"
+		} else if v.IsB() {
+			addFloat("exclusive minimum", &v.B)
+		}
+	}
+	if v := s.ExclusiveMaximum; v != nil {
+		if v.IsA() && v.A {
+			maximum = "exclusive maximum"
+		} else if v.IsB() {
+			addFloat("exclusive maximum", &v.B)
+		}
+	}
+	addFloat(minimum, s.Minimum)
+	addFloat(maximum, s.Maximum)
+	addFloat("multiple of", s.MultipleOf)
+
+	addInt("min length", s.MinLength)
+	addInt("max length", s.MaxLength)
+	if s.Pattern != "" {
+		constraints = append(constraints, "pattern "+s.Pattern)
+	}
+
+	addInt("min items", s.MinItems)
+	addInt("max items", s.MaxItems)
+	if fromBoolPtr(s.UniqueItems) {
+		constraints = append(constraints, "unique items")
+	}
+
+	addInt("min properties", s.MinProperties)
+	addInt("max properties", s.MaxProperties)
+
+	return constraints
+}
+
+// exampleLiteral returns the example as compact JSON.
+func exampleLiteral(n *yaml.Node) (string, error) {
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Comment returns the doc comment of the field, if it has any documentation.
+// The description comes first, followed by a paragraph with the format,
+// constraints and example and by the deprecation notice.
+func (f Field) Comment() string {
+	var details []string
+	if f.Format != "" {
+		details = append(details, "Format: "+f.Format+".")
+	}
+	if len(f.Constraints) > 0 {
+		details = append(details, "Constraints: "+strings.Join(f.Constraints, ", ")+".")
+	}
+	if f.Example != "" {
+		details = append(details, "Example: "+f.Example)
+	}
+
+	var paragraphs []string
+	for _, v := range []string{f.Description, strings.Join(details, " ")} {
+		if v := formatComment(v); v != "" {
+			paragraphs = append(paragraphs, v)
+		}
+	}
+	if f.Deprecated {
+		paragraphs = append(paragraphs, formatComment("Deprecated: "+f.Name+" is deprecated."))
+	}
+
+	return strings.Join(paragraphs, "\n//\n")
+}
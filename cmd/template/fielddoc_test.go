@@ -0,0 +1,77 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaConstraints(t *testing.T) {
+	zero, hundred := 0.0, 100.0
+	var ten int64 = 10
+
+	tests := []struct {
+		desc     string
+		schema   *base.Schema
+		expected []string
+	}{
+		{"none", &base.Schema{}, nil},
+		{
+			"3.0 exclusive bounds",
+			&base.Schema{
+				Minimum:          &zero,
+				ExclusiveMinimum: &base.DynamicValue[bool, float64]{A: true},
+				Maximum:          &hundred,
+				ExclusiveMaximum: &base.DynamicValue[bool, float64]{A: false},
+			},
+			[]string{"exclusive minimum 0", "maximum 100"},
+		},
+		{
+			"3.1 exclusive bounds",
+			&base.Schema{
+				ExclusiveMinimum: &base.DynamicValue[bool, float64]{N: 1, B: 0},
+				ExclusiveMaximum: &base.DynamicValue[bool, float64]{N: 1, B: 100},
+			},
+			[]string{"exclusive minimum 0", "exclusive maximum 100"},
+		},
+		{
+			"strings",
+			&base.Schema{MaxLength: &ten, Pattern: "^[a-z]+$"},
+			[]string{"max length 10", "pattern ^[a-z]+$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.expected, schemaConstraints(tt.schema))
+		})
+	}
+}
+
+func TestFieldComment(t *testing.T) {
+	tests := []struct {
+		desc     string
+		field    Field
+		expected string
+	}{
+		{"none", Field{Name: "ID"}, ""},
+		{"description", Field{Name: "ID", Description: "The ID."}, "// The ID."},
+		{
+			"details",
+			Field{Name: "ID", Format: "uuid", Constraints: []string{"min length 1"}, Example: `"abc"`},
+			`// Format: uuid. Constraints: min length 1. Example: "abc"`,
+		},
+		{
+			"all",
+			Field{Name: "ID", Description: "The ID.", Format: "uuid", Deprecated: true},
+			"// The ID.\n//\n// Format: uuid.\n//\n// Deprecated: ID is deprecated.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.field.Comment())
+		})
+	}
+}
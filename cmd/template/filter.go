@@ -0,0 +1,473 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v4"
+)
+
+const extensionInternal = "x-internal"
+
+// operationFilter selects the operations code is generated for. An operation
+// is kept if it matches at least one of the include filters, when there are
+// any, and none of the exclude filters.
+type operationFilter struct {
+	includeTags       []string
+	excludeTags       []string
+	includeOperations []string // operationId globs
+	excludeOperations []string // operationId globs
+	includePaths      []string // path prefixes
+	excludePaths      []string // path prefixes
+
+	// excludeInternal excludes the operations and path items marked with
+	// x-internal: true.
+	excludeInternal bool
+
+	// config is the path of a filter config file, whose filters are added to
+	// the ones set with flags.
+	config string
+}
+
+// filterConfig is the format of a filter config file, e.g.
+//
+//	include:
+//	  tags: [widgets]
+//	  operations: ["widgets*"]
+//	  paths: [/v1/]
+//	exclude:
+//	  tags: [admin]
+//	  operations: ["*Delete"]
+//	  paths: [/v1/internal/]
+//	  internal: true
+type filterConfig struct {
+	Include includeConfig `yaml:"include"`
+	Exclude excludeConfig `yaml:"exclude"`
+}
+
+type includeConfig struct {
+	Tags       []string `yaml:"tags"`
+	Operations []string `yaml:"operations"`
+	Paths      []string `yaml:"paths"`
+}
+
+type excludeConfig struct {
+	Tags       []string `yaml:"tags"`
+	Operations []string `yaml:"operations"`
+	Paths      []string `yaml:"paths"`
+	Internal   bool     `yaml:"internal"`
+}
+
+func addFilterFlags(cmd *cobra.Command, f *operationFilter) {
+	flags := cmd.Flags()
+	flags.StringArrayVar(&f.includeTags, "include-tag", nil, "Only generates the operations with this tag. Can be repeated.")
+	flags.StringArrayVar(&f.excludeTags, "exclude-tag", nil, "Doesn't generate the operations with this tag. Can be repeated.")
+	flags.StringArrayVar(&f.includeOperations, "include-operation", nil, "Only generates the operations whose operationId matches this glob. Can be repeated.")
+	flags.StringArrayVar(&f.excludeOperations, "exclude-operation", nil, "Doesn't generate the operations whose operationId matches this glob. Can be repeated.")
+	flags.StringArrayVar(&f.includePaths, "include-path", nil, "Only generates the operations whose path starts with this prefix. Can be repeated.")
+	flags.StringArrayVar(&f.excludePaths, "exclude-path", nil, "Doesn't generate the operations whose path starts with this prefix. Can be repeated.")
+	flags.BoolVar(&f.excludeInternal, "exclude-internal", false, "When true, doesn't generate the operations marked with "+extensionInternal+": true.")
+	flags.StringVar(&f.config, "filter-config", "", "A YAML file of include and exclude filters, added to the ones set with flags.")
+}
+
+// withConfig returns the filter with the filters of its config file added.
+func (f operationFilter) withConfig() (operationFilter, error) {
+	if f.config == "" {
+		return f, nil
+	}
+
+	file, err := os.Open(f.config)
+	if err != nil {
+		return f, fmt.Errorf("loading filter config: %w", err)
+	}
+	defer file.Close()
+
+	// Unknown keys are rejected so a misspelled filter isn't silently ignored.
+	var c filterConfig
+	dec := yaml.NewDecoder(file)
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return f, fmt.Errorf("parsing filter config %q: %w", f.config, err)
+	}
+
+	f.includeTags = slices.Concat(f.includeTags, c.Include.Tags)
+	f.includeOperations = slices.Concat(f.includeOperations, c.Include.Operations)
+	f.includePaths = slices.Concat(f.includePaths, c.Include.Paths)
+	f.excludeTags = slices.Concat(f.excludeTags, c.Exclude.Tags)
+	f.excludeOperations = slices.Concat(f.excludeOperations, c.Exclude.Operations)
+	f.excludePaths = slices.Concat(f.excludePaths, c.Exclude.Paths)
+	f.excludeInternal = f.excludeInternal || c.Exclude.Internal
+	f.config = ""
+
+	return f, nil
+}
+
+// active reports whether any filter is set.
+func (f operationFilter) active() bool {
+	return f.excludeInternal || f.config != "" || len(slices.Concat(
+		f.includeTags,
+		f.excludeTags,
+		f.includeOperations,
+		f.excludeOperations,
+		f.includePaths,
+		f.excludePaths,
+	)) > 0
+}
+
+func (f operationFilter) validate() error {
+	for _, v := range slices.Concat(f.includeOperations, f.excludeOperations) {
+		if _, err := path.Match(v, ""); err != nil {
+			return fmt.Errorf("operation glob %q: %w", v, err)
+		}
+	}
+	return nil
+}
+
+// keep reports whether the operation passes the filters.
+func (f operationFilter) keep(p string, pi *v3high.PathItem, op *v3high.Operation) (bool, error) {
+	if f.excludeInternal {
+		for _, ext := range []*orderedmap.Map[string, *yaml.Node]{pi.Extensions, op.Extensions} {
+			internal, err := getExtensionBool(ext, extensionInternal)
+			if err != nil {
+				return false, err
+			}
+			if internal {
+				return false, nil
+			}
+		}
+	}
+
+	matchTag := func(tags []string) bool {
+		return slices.ContainsFunc(op.Tags, func(t string) bool { return slices.Contains(tags, t) })
+	}
+	matchOperation := func(globs []string) bool {
+		return slices.ContainsFunc(globs, func(g string) bool {
+			ok, _ := path.Match(g, op.OperationId)
+			return ok
+		})
+	}
+	matchPath := func(prefixes []string) bool {
+		return slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(p, prefix) })
+	}
+
+	if matchTag(f.excludeTags) || matchOperation(f.excludeOperations) || matchPath(f.excludePaths) {
+		return false, nil
+	}
+
+	if len(f.includeTags) == 0 && len(f.includeOperations) == 0 && len(f.includePaths) == 0 {
+		return true, nil
+	}
+
+	return matchTag(f.includeTags) || matchOperation(f.includeOperations) || matchPath(f.includePaths), nil
+}
+
+// filterDocument removes the operations that don't pass the filters from the
+// document, along with the path items left without operations and the
+// component schemas only reachable from the removed operations. Component
+// schemas that weren't reachable from any operation to begin with are kept.
+func filterDocument(doc *v3high.Document, f operationFilter) error {
+	f, err := f.withConfig()
+	if err != nil {
+		return err
+	}
+	if err := f.validate(); err != nil {
+		return err
+	}
+
+	reachable := reachableSchemas(doc)
+
+	if doc.Paths != nil && doc.Paths.PathItems != nil {
+		var emptyPaths []string
+		for pair := doc.Paths.PathItems.First(); pair != nil; pair = pair.Next() {
+			p := pair.Key()
+			pi := pair.Value()
+
+			for opPair := pi.GetOperations().First(); opPair != nil; opPair = opPair.Next() {
+				keep, err := f.keep(p, pi, opPair.Value())
+				if err != nil {
+					return fmt.Errorf("%s %s: %w", opPair.Key(), p, err)
+				}
+				if !keep {
+					removeOperation(pi, opPair.Key())
+				}
+			}
+
+			if pi.GetOperations().Len() == 0 {
+				emptyPaths = append(emptyPaths, p)
+			}
+		}
+
+		for _, p := range emptyPaths {
+			doc.Paths.PathItems.Delete(p)
+		}
+	}
+
+	pruneSchemas(doc, reachable)
+	return nil
+}
+
+func removeOperation(pi *v3high.PathItem, method string) {
+	switch strings.ToLower(method) {
+	case "get":
+		pi.Get = nil
+	case "put":
+		pi.Put = nil
+	case "post":
+		pi.Post = nil
+	case "delete":
+		pi.Delete = nil
+	case "options":
+		pi.Options = nil
+	case "head":
+		pi.Head = nil
+	case "patch":
+		pi.Patch = nil
+	case "trace":
+		pi.Trace = nil
+	case "query":
+		pi.Query = nil
+	default:
+		if pi.AdditionalOperations != nil {
+			pi.AdditionalOperations.Delete(method)
+		}
+	}
+}
+
+// pruneSchemas removes the component schemas that were reachable before the
+// document was filtered but no longer are. The schemas that weren't reachable
+// before are kept, along with the schemas they reference.
+func pruneSchemas(doc *v3high.Document, before map[string]bool) {
+	if doc.Components == nil || doc.Components.Schemas == nil {
+		return
+	}
+
+	var standalone []string
+	for name := range doc.Components.Schemas.KeysFromOldest() {
+		if !before[name] {
+			standalone = append(standalone, name)
+		}
+	}
+	after := reachableSchemas(doc, standalone...)
+
+	var unreachable []string
+	for name := range before {
+		if !after[name] {
+			unreachable = append(unreachable, name)
+		}
+	}
+	for _, name := range unreachable {
+		doc.Components.Schemas.Delete(name)
+	}
+}
+
+// reachableSchemas returns the names of the component schemas reachable from
+// the paths, webhooks or the other components of the document, or from the
+// given component schemas.
+func reachableSchemas(doc *v3high.Document, schemas ...string) map[string]bool {
+	if doc.Components == nil || doc.Components.Schemas == nil {
+		return nil
+	}
+
+	r := schemaReachability{
+		schemas: doc.Components.Schemas,
+		reached: make(map[string]bool),
+	}
+
+	for _, name := range schemas {
+		r.reference(componentSchemaPrefix + name)
+	}
+	if doc.Paths != nil && doc.Paths.PathItems != nil {
+		for pi := range doc.Paths.PathItems.ValuesFromOldest() {
+			r.pathItem(pi)
+		}
+	}
+	if doc.Webhooks != nil {
+		for pi := range doc.Webhooks.ValuesFromOldest() {
+			r.pathItem(pi)
+		}
+	}
+
+	c := doc.Components
+	if c.Responses != nil {
+		for v := range c.Responses.ValuesFromOldest() {
+			r.response(v)
+		}
+	}
+	if c.Parameters != nil {
+		for v := range c.Parameters.ValuesFromOldest() {
+			r.parameter(v)
+		}
+	}
+	if c.RequestBodies != nil {
+		for v := range c.RequestBodies.ValuesFromOldest() {
+			r.content(v.Content)
+		}
+	}
+	if c.Headers != nil {
+		for v := range c.Headers.ValuesFromOldest() {
+			r.header(v)
+		}
+	}
+	if c.Callbacks != nil {
+		for v := range c.Callbacks.ValuesFromOldest() {
+			r.callback(v)
+		}
+	}
+	if c.PathItems != nil {
+		for v := range c.PathItems.ValuesFromOldest() {
+			r.pathItem(v)
+		}
+	}
+
+	return r.reached
+}
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+// schemaReachability walks the schemas referenced by the parts of a document,
+// recording the component schemas reached.
+type schemaReachability struct {
+	schemas *orderedmap.Map[string, *base.SchemaProxy]
+	reached map[string]bool
+}
+
+func (r *schemaReachability) pathItem(pi *v3high.PathItem) {
+	if pi == nil {
+		return
+	}
+	for _, v := range pi.Parameters {
+		r.parameter(v)
+	}
+	for op := range pi.GetOperations().ValuesFromOldest() {
+		for _, v := range op.Parameters {
+			r.parameter(v)
+		}
+		if op.RequestBody != nil {
+			r.content(op.RequestBody.Content)
+		}
+		if op.Responses != nil {
+			r.response(op.Responses.Default)
+			if op.Responses.Codes != nil {
+				for v := range op.Responses.Codes.ValuesFromOldest() {
+					r.response(v)
+				}
+			}
+		}
+		if op.Callbacks != nil {
+			for v := range op.Callbacks.ValuesFromOldest() {
+				r.callback(v)
+			}
+		}
+	}
+}
+
+func (r *schemaReachability) callback(c *v3high.Callback) {
+	if c == nil || c.Expression == nil {
+		return
+	}
+	for pi := range c.Expression.ValuesFromOldest() {
+		r.pathItem(pi)
+	}
+}
+
+func (r *schemaReachability) parameter(p *v3high.Parameter) {
+	if p == nil {
+		return
+	}
+	r.schema(p.Schema)
+	r.content(p.Content)
+}
+
+func (r *schemaReachability) header(h *v3high.Header) {
+	if h == nil {
+		return
+	}
+	r.schema(h.Schema)
+	r.content(h.Content)
+}
+
+func (r *schemaReachability) response(resp *v3high.Response) {
+	if resp == nil {
+		return
+	}
+	if resp.Headers != nil {
+		for v := range resp.Headers.ValuesFromOldest() {
+			r.header(v)
+		}
+	}
+	r.content(resp.Content)
+}
+
+func (r *schemaReachability) content(content *orderedmap.Map[string, *v3high.MediaType]) {
+	if content == nil {
+		return
+	}
+	for mt := range content.ValuesFromOldest() {
+		r.schema(mt.Schema)
+		r.schema(mt.ItemSchema)
+	}
+}
+
+// reference records a reference to a component schema, walking the schema the
+// first time it's reached.
+func (r *schemaReachability) reference(ref string) {
+	name, ok := strings.CutPrefix(ref, componentSchemaPrefix)
+	if !ok || r.reached[name] {
+		return
+	}
+	r.reached[name] = true
+	if sp, ok := r.schemas.Get(name); ok {
+		r.walk(sp.Schema())
+	}
+}
+
+func (r *schemaReachability) schema(sp *base.SchemaProxy) {
+	if sp == nil {
+		return
+	}
+	if sp.IsReference() {
+		r.reference(sp.GetReference())
+		return
+	}
+	r.walk(sp.Schema())
+}
+
+func (r *schemaReachability) walk(s *base.Schema) {
+	if s == nil {
+		return
+	}
+
+	for _, v := range slices.Concat(s.AllOf, s.OneOf, s.AnyOf, s.PrefixItems) {
+		r.schema(v)
+	}
+	for _, v := range []*base.SchemaProxy{s.Contains, s.If, s.Else, s.Then, s.PropertyNames, s.UnevaluatedItems, s.Not} {
+		r.schema(v)
+	}
+	for _, v := range []*base.DynamicValue[*base.SchemaProxy, bool]{s.Items, s.AdditionalProperties, s.UnevaluatedProperties} {
+		if v != nil && v.IsA() {
+			r.schema(v.A)
+		}
+	}
+	for _, m := range []*orderedmap.Map[string, *base.SchemaProxy]{s.Properties, s.PatternProperties, s.DependentSchemas} {
+		if m == nil {
+			continue
+		}
+		for v := range m.ValuesFromOldest() {
+			r.schema(v)
+		}
+	}
+	if s.Discriminator != nil && s.Discriminator.Mapping != nil {
+		for ref := range s.Discriminator.Mapping.ValuesFromOldest() {
+			r.reference(ref)
+		}
+	}
+}
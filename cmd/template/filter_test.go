@@ -0,0 +1,163 @@
+package template
+
+import (
+	"slices"
+	"testing"
+
+	version "github.com/jasonhancock/cobra-version"
+	"github.com/jasonhancock/jasongen/internal/loader"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterDocument(t *testing.T) {
+	tests := []struct {
+		desc       string
+		filter     operationFilter
+		operations []string
+		models     []string
+		err        string
+	}{
+		{
+			"none",
+			operationFilter{},
+			[]string{"widgetsList", "widgetsCreate", "adminAuditList", "adminUsersDelete"},
+			[]string{"Widget", "Owner", "WidgetCreate", "AuditEvent", "AuditKind", "Team"},
+			"",
+		},
+		{
+			"exclude internal",
+			operationFilter{excludeInternal: true},
+			[]string{"widgetsList", "adminUsersDelete"},
+			[]string{"Widget", "Owner", "Team"},
+			"",
+		},
+		{
+			"include tag",
+			operationFilter{includeTags: []string{"admin"}},
+			[]string{"adminAuditList", "adminUsersDelete"},
+			[]string{"Owner", "AuditEvent", "AuditKind", "Team"},
+			"",
+		},
+		{
+			"exclude tag",
+			operationFilter{excludeTags: []string{"admin"}},
+			[]string{"widgetsList", "widgetsCreate"},
+			[]string{"Widget", "Owner", "WidgetCreate", "Team"},
+			"",
+		},
+		{
+			"include operation glob",
+			operationFilter{includeOperations: []string{"widgets*"}},
+			[]string{"widgetsList", "widgetsCreate"},
+			[]string{"Widget", "Owner", "WidgetCreate", "Team"},
+			"",
+		},
+		{
+			"exclude operation glob",
+			operationFilter{excludeOperations: []string{"*Delete", "widgetsCreate"}},
+			[]string{"widgetsList", "adminAuditList"},
+			[]string{"Widget", "Owner", "AuditEvent", "AuditKind", "Team"},
+			"",
+		},
+		{
+			"include path prefix",
+			operationFilter{includePaths: []string{"/admin/"}},
+			[]string{"adminAuditList", "adminUsersDelete"},
+			[]string{"Owner", "AuditEvent", "AuditKind", "Team"},
+			"",
+		},
+		{
+			"include and exclude",
+			operationFilter{includePaths: []string{"/v1/"}, includeTags: []string{"admin"}, excludePaths: []string{"/admin/users"}},
+			[]string{"widgetsList", "widgetsCreate", "adminAuditList"},
+			[]string{"Widget", "Owner", "WidgetCreate", "AuditEvent", "AuditKind", "Team"},
+			"",
+		},
+		{
+			"nothing left",
+			operationFilter{includeTags: []string{"gadgets"}},
+			nil,
+			[]string{"Team", "Owner"},
+			"",
+		},
+		{
+			"unreferenced schema kept",
+			operationFilter{includeOperations: []string{"adminUsersDelete"}},
+			[]string{"adminUsersDelete"},
+			[]string{"Team", "Owner"},
+			"",
+		},
+		{
+			"config",
+			operationFilter{config: "testdata/filter/config.yaml"},
+			[]string{"widgetsList", "adminUsersDelete"},
+			[]string{"Widget", "Owner", "Team"},
+			"",
+		},
+		{
+			"config and flags",
+			operationFilter{config: "testdata/filter/config.yaml", excludeOperations: []string{"*Delete"}},
+			[]string{"widgetsList"},
+			[]string{"Widget", "Owner", "Team"},
+			"",
+		},
+		{
+			"missing config",
+			operationFilter{config: "testdata/filter/missing.yaml"},
+			nil,
+			nil,
+			"loading filter config: open testdata/filter/missing.yaml: no such file or directory",
+		},
+		{
+			"unknown config key",
+			operationFilter{config: "testdata/filter/config_unknown.yaml"},
+			nil,
+			nil,
+			`parsing filter config "testdata/filter/config_unknown.yaml": yaml: construct errors:
+  line 2: field tag not found in type template.excludeConfig`,
+		},
+		{
+			"bad glob",
+			operationFilter{includeOperations: []string{"widgets["}},
+			nil,
+			nil,
+			`operation glob "widgets[": syntax error in pattern`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			doc, err := loader.MergeAndLoad("testdata/openapi_base.yaml", "testdata/filter/openapi.yaml")
+			require.NoError(t, err)
+
+			err = filterDocument(&doc.Model, tt.filter)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			td, err := templateDataFrom(doc, "widgets", version.Info{}, cmdOptions{language: "go"})
+			require.NoError(t, err)
+
+			var operations []string
+			for _, h := range td.Handlers {
+				operations = append(operations, h.Name)
+			}
+			require.ElementsMatch(t, tt.operations, operations)
+
+			var models []string
+			for _, m := range td.Models {
+				models = append(models, m.Name)
+			}
+			require.ElementsMatch(t, tt.models, models)
+
+			// The embedded spec is filtered as well.
+			for _, name := range []string{"adminAuditList", "AuditEvent"} {
+				if !slices.Contains(operations, name) && !slices.Contains(models, name) {
+					require.NotContains(t, td.Spec, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/jasonhancock/jasongen/internal/loader"
+	"github.com/spf13/cobra"
+)
+
+// fingerprint identifies the inputs a file was generated from: the template
+// and a hash of the merged spec and of the template content.
+type fingerprint struct {
+	Template string
+	Language string
+	Sum      string
+}
+
+// String returns the fingerprint the way it's written in the header of the
+// generated files. A template read from a path with spaces or other special
+// characters is written as a quoted Go string.
+func (f fingerprint) String() string {
+	tmpl := f.Template
+	if q := strconv.Quote(tmpl); tmpl == "" || q != `"`+tmpl+`"` || strings.ContainsAny(tmpl, " \t") {
+		tmpl = q
+	}
+	return tmpl + " " + f.Language + " " + f.Sum
+}
+
+var fingerprintLine = regexp.MustCompile(`(?m)^// Fingerprint: ("(?:[^"\\]|\\.)*"|[^\s"]\S*) (\S+) ([0-9a-f]{64})\r?$`)
+
+// readFingerprint returns the fingerprint found in the header of a generated
+// file.
+func readFingerprint(data []byte) (fingerprint, bool) {
+	m := fingerprintLine.FindSubmatch(data)
+	if m == nil {
+		return fingerprint{}, false
+	}
+
+	tmpl := string(m[1])
+	if strings.HasPrefix(tmpl, `"`) {
+		var err error
+		tmpl, err = strconv.Unquote(tmpl)
+		if err != nil {
+			return fingerprint{}, false
+		}
+	}
+	return fingerprint{Template: tmpl, Language: string(m[2]), Sum: string(m[3])}, true
+}
+
+// newFingerprint hashes the rendered spec along with the parse trees of the
+// template and of the blocks it invokes. Hashing the parse trees instead of the
+// template files means comments and unused partials don't affect it.
+func newFingerprint(tmpl, language, spec string, t *template.Template) fingerprint {
+	h := sha256.New()
+	io.WriteString(h, spec)
+
+	var (
+		hashed []string
+		queue  = []string{t.Name()}
+	)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if slices.Contains(hashed, name) {
+			continue
+		}
+		hashed = append(hashed, name)
+
+		v := t.Lookup(name)
+		if v == nil || v.Tree == nil {
+			continue
+		}
+		fmt.Fprintf(h, "\x00%s\x00%s", name, v.Tree.Root.String())
+		queue = append(queue, appendInvoked(nil, v.Tree.Root)...)
+	}
+
+	return fingerprint{
+		Template: tmpl,
+		Language: language,
+		Sum:      hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+// NewStaleCmd sets up the command reporting generated files that are out of
+// date.
+func NewStaleCmd() *cobra.Command {
+	var opts cmdOptions
+
+	cmd := &cobra.Command{
+		Use:          "stale <dir> <file1> <file2> ... <fileN>",
+		Short:        "Reports the generated files whose fingerprint doesn't match the spec and templates",
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stale, err := findStale(cmd.OutOrStdout(), args[0], opts, args[1:]...)
+			if err != nil {
+				return err
+			}
+			if len(stale) > 0 {
+				return fmt.Errorf("%d stale file(s)", len(stale))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(
+		&opts.templateDirs,
+		"template-dir",
+		nil,
+		"A directory of templates and partials layered over the embedded templates, as passed when generating the files. Can be repeated.",
+	)
+
+	// The filters are part of the spec the files were generated from.
+	addFilterFlags(cmd, &opts.filter)
+
+	return cmd
+}
+
+// findStale scans dir for generated files and returns the ones whose
+// fingerprint doesn't match the spec files and the templates, reporting them to
+// out.
+func findStale(out io.Writer, dir string, opts cmdOptions, files ...string) ([]string, error) {
+	result, err := loader.MergeAndLoad(files...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.filter.active() {
+		if err := filterDocument(&result.Model, opts.filter); err != nil {
+			return nil, err
+		}
+	}
+	spec, err := result.Model.Render()
+	if err != nil {
+		return nil, fmt.Errorf("rendering spec: %w", err)
+	}
+
+	// The current fingerprints, by template and language.
+	current := make(map[[2]string]fingerprint)
+
+	var stale []string
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !slices.Contains([]string{".go", ".js"}, filepath.Ext(p)) {
+			return err
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		found, ok := readFingerprint(data)
+		if !ok {
+			return nil
+		}
+
+		key := [2]string{found.Template, found.Language}
+		fp, ok := current[key]
+		if !ok {
+			o := opts
+			o.language = found.Language
+			t, err := parseTemplate(found.Template, o)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			fp = newFingerprint(found.Template, found.Language, string(spec), t)
+			current[key] = fp
+		}
+
+		if fp != found {
+			stale = append(stale, p)
+			if _, err := fmt.Fprintf(out, "%s: stale (%s %s)\n", p, found.Template, found.Language); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return stale, err
+}
@@ -0,0 +1,138 @@
+package template
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	version "github.com/jasonhancock/cobra-version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFingerprint(t *testing.T) {
+	sum := "07ae1cce2d88ddfede27e384792e49c208b49596a72dd760f754ae50d19d5770"
+
+	tests := []struct {
+		description string
+		data        string
+		expected    fingerprint
+		found       bool
+	}{
+		{
+			"go",
+			"// Code generated by jasongen. DO NOT EDIT.\n// jasongen 1.2.3\n// Fingerprint: models go " + sum + "\n\npackage widgets\n",
+			fingerprint{"models", "go", sum},
+			true,
+		},
+		{
+			"crlf",
+			"// Fingerprint: client js " + sum + "\r\n",
+			fingerprint{"client", "js", sum},
+			true,
+		},
+		{
+			"quoted path",
+			"// Fingerprint: \"my templates/models.go.tmpl\" go " + sum + "\n",
+			fingerprint{"my templates/models.go.tmpl", "go", sum},
+			true,
+		},
+		{"missing", "package widgets\n", fingerprint{}, false},
+		{"truncated sum", "// Fingerprint: models go 07ae1cce\n", fingerprint{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			fp, found := readFingerprint([]byte(tt.data))
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.expected, fp)
+			if found {
+				// The fingerprint is written back as it was read.
+				line, _, _ := strings.Cut(tt.data[strings.Index(tt.data, "// Fingerprint: "):], "\n")
+				line = strings.TrimSuffix(line, "\r")
+				require.Equal(t, "// Fingerprint: "+fp.String(), line)
+			}
+		})
+	}
+}
+
+func TestFindStale(t *testing.T) {
+	dir := t.TempDir()
+	specs := []string{"testdata/openapi_base.yaml", "testdata/cases/all/openapi.yaml"}
+
+	for _, tmpl := range []string{"models", "service_logging", "service"} {
+		err := runTemplate(
+			"widgets",
+			tmpl,
+			filepath.Join(dir, tmpl+".go"),
+			cmdOptions{overwrite: true, language: "go"},
+			version.Info{Version: "1.2.3"},
+			specs...,
+		)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("// Fingerprint: nope"), 0644))
+
+	stale, err := findStale(io.Discard, dir, cmdOptions{}, specs...)
+	require.NoError(t, err)
+	require.Empty(t, stale)
+
+	// Comments don't affect the fingerprint.
+	tmplDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tmplDir, "service_logging.go.tmpl"),
+		[]byte("{{- /* Just a comment */ -}}\n"),
+		0644,
+	))
+	stale, err = findStale(io.Discard, dir, cmdOptions{templateDirs: []string{tmplDir}}, specs...)
+	require.NoError(t, err)
+	require.Empty(t, stale)
+
+	// Overriding a template or partial only affects the files rendered from
+	// it.
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tmplDir, "models.go.tmpl"),
+		[]byte("package {{ .PackageName }}\n"),
+		0644,
+	))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmplDir, "partials"), 0755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tmplDir, "partials", "param_int.go.tmpl"),
+		[]byte(`{{ define "param_int" }}{{ end }}`),
+		0644,
+	))
+	stale, err = findStale(io.Discard, dir, cmdOptions{templateDirs: []string{tmplDir}}, specs...)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "models.go")}, stale)
+
+	// A different spec makes every generated file stale. The service scaffold
+	// is yours to edit, so it doesn't carry a fingerprint.
+	stale, err = findStale(io.Discard, dir, cmdOptions{}, "testdata/openapi_base.yaml", "testdata/cases/methods/openapi.yaml")
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "models.go"), filepath.Join(dir, "service_logging.go")}, stale)
+}
+
+func TestFindStaleTemplatePathWithSpaces(t *testing.T) {
+	dir := t.TempDir()
+	specs := []string{"testdata/openapi_base.yaml", "testdata/cases/methods/openapi.yaml"}
+
+	tmpl := filepath.Join(t.TempDir(), "my templates", "custom models.tmpl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(tmpl), 0755))
+	data, err := os.ReadFile("templates/models.go.tmpl")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(tmpl, data, 0644))
+
+	err = runTemplate("widgets", tmpl, filepath.Join(dir, "models.go"), cmdOptions{overwrite: true, language: "go"}, version.Info{Version: "1.2.3"}, specs...)
+	require.NoError(t, err)
+
+	stale, err := findStale(io.Discard, dir, cmdOptions{}, specs...)
+	require.NoError(t, err)
+	require.Empty(t, stale)
+
+	// Editing the template makes the file stale.
+	require.NoError(t, os.WriteFile(tmpl, append(data, []byte("\n// {{ .PackageName }}\n")...), 0644))
+	stale, err = findStale(io.Discard, dir, cmdOptions{}, specs...)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "models.go")}, stale)
+}
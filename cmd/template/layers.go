@@ -0,0 +1,112 @@
+package template
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"text/template"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// templateLayer is a directory of templates. Partials, files containing only
+// {{define}} blocks shared by the templates, live in its partials directory.
+type templateLayer struct {
+	name string
+	fsys fs.FS
+}
+
+// templateLayers returns the embedded templates followed by the template
+// directories of the options, from the lowest to the highest precedence.
+func templateLayers(opts cmdOptions) ([]templateLayer, error) {
+	embedded, err := fs.Sub(templates, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []templateLayer{{name: "embedded templates", fsys: embedded}}
+	for _, dir := range opts.templateDirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("template dir: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("template dir %s is not a directory", dir)
+		}
+		layers = append(layers, templateLayer{name: dir, fsys: os.DirFS(dir)})
+	}
+
+	return layers, nil
+}
+
+// parseTemplate parses the template named tmpl and the partials of every
+// layer. Each layer is parsed over the layers below it: a template or partial
+// containing only {{define}} blocks replaces the blocks of the same name, so a
+// single block can be customized without copying the whole template, while a
+// template with a body replaces the body of the template entirely. If no layer
+// has the template, tmpl is read as the path of a template file.
+func parseTemplate(tmpl string, opts cmdOptions) (*template.Template, error) {
+	layers, err := templateLayers(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	t := template.New(tmpl).Funcs(templateFuncs(opts.pkgModels))
+	parse := func(name, text string) error {
+		if _, err := t.Parse(text); err != nil {
+			return fmt.Errorf("parsing %s: %w", name, err)
+		}
+		return nil
+	}
+
+	var found bool
+	for _, layer := range layers {
+		partials, err := fs.Glob(layer.fsys, path.Join("partials", "*."+opts.language+".tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range partials {
+			data, err := fs.ReadFile(layer.fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			if err := parse(path.Join(layer.name, name), string(data)); err != nil {
+				return nil, err
+			}
+		}
+
+		// A template given as an absolute or parent relative path is read
+		// from the file system below.
+		name := tmpl + "." + opts.language + ".tmpl"
+		if !fs.ValidPath(name) {
+			continue
+		}
+		data, err := fs.ReadFile(layer.fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := parse(path.Join(layer.name, name), string(data)); err != nil {
+			return nil, err
+		}
+		found = true
+	}
+
+	if !found {
+		data, err := os.ReadFile(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		if err := parse(tmpl, string(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
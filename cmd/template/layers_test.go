@@ -0,0 +1,76 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	version "github.com/jasonhancock/cobra-version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTemplateLayers(t *testing.T) {
+	writeFile := func(t *testing.T, name, data string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.NoError(t, os.WriteFile(name, []byte(data), 0644))
+	}
+
+	lower := t.TempDir()
+	upper := t.TempDir()
+
+	// A block of an embedded template and an embedded partial are overridden.
+	writeFile(t, filepath.Join(lower, "http_server.go.tmpl"), `{{ define "handler" }}
+	// custom handler for {{ .Name }}
+{{ end }}`)
+	writeFile(t, filepath.Join(upper, "partials", "param_int.go.tmpl"), `{{ define "param_int" }}
+	// custom int param {{ .Name }}
+{{ end }}`)
+
+	// A template of its own using partials from all of the layers.
+	writeFile(t, filepath.Join(lower, "partials", "greeting.go.tmpl"), `{{ define "greeting" }}// Hello from {{ . }}{{ end }}`)
+	writeFile(t, filepath.Join(upper, "custom.go.tmpl"), `package {{ .PackageName }}
+
+{{ template "greeting" .PackageName }}
+{{ range .Handlers }}{{ range .Params }}{{ if eq .Location "path" }}{{ if ne .Type "string" }}
+func f() {
+{{ template "param_int" . }}
+}
+{{ end }}{{ end }}{{ end }}{{ end }}`)
+
+	tests := []struct {
+		tmpl     string
+		expected []string
+	}{
+		{"http_server", []string{"// custom handler for widgetGet", "func NewHTTPServer("}},
+		{"custom", []string{"// Hello from widgets", "// custom int param num"}},
+		{filepath.Join(upper, "custom.go.tmpl"), []string{"// Hello from widgets", "// custom int param num"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			outfile := filepath.Join(t.TempDir(), "out.go")
+			err := runTemplate(
+				"widgets",
+				tt.tmpl,
+				outfile,
+				cmdOptions{overwrite: true, language: "go", templateDirs: []string{lower, upper}},
+				version.Info{Version: "1.2.3"},
+				"testdata/openapi_base.yaml",
+				"testdata/cases/all/openapi.yaml",
+			)
+			require.NoError(t, err)
+
+			b, err := os.ReadFile(outfile)
+			require.NoError(t, err)
+			for _, v := range tt.expected {
+				require.Contains(t, string(b), v)
+			}
+		})
+	}
+
+	t.Run("missing dir", func(t *testing.T) {
+		_, err := parseTemplate("http_server", cmdOptions{language: "go", templateDirs: []string{filepath.Join(lower, "nope")}})
+		require.ErrorContains(t, err, "template dir")
+	})
+}
@@ -0,0 +1,285 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/imports"
+)
+
+// mergeReport lists the methods a merge added to, changed in or found orphaned
+// in the existing file.
+type mergeReport struct {
+	Added    []string
+	Changed  []string
+	Orphaned []string
+}
+
+func (r mergeReport) write(out io.Writer, file string) error {
+	for _, v := range []struct {
+		label   string
+		methods []string
+	}{
+		{"added", r.Added},
+		{"changed", r.Changed},
+		{"orphaned", r.Orphaned},
+	} {
+		for _, m := range v.methods {
+			if _, err := fmt.Fprintf(out, "%s: %s %s\n", file, v.label, m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// goMethod is a method declared in a Go file.
+type goMethod struct {
+	decl *ast.FuncDecl
+
+	// signature is the printed receiver, parameter and result types. The
+	// names are left out, so renaming a parameter doesn't change it.
+	signature string
+}
+
+// param is a receiver, parameter or result of a method.
+type param struct {
+	// name is nil when the param is unnamed.
+	name *ast.Ident
+	typ  string
+}
+
+// params flattens a field list into one param per name, so a, b string and
+// a string, b string give the same params.
+func params(fl *ast.FieldList) []param {
+	if fl == nil {
+		return nil
+	}
+	var out []param
+	for _, f := range fl.List {
+		// ExprString ignores the positions, so the types compare equal
+		// regardless of how they are wrapped.
+		typ := types.ExprString(f.Type)
+		if len(f.Names) == 0 {
+			out = append(out, param{typ: typ})
+			continue
+		}
+		for _, n := range f.Names {
+			out = append(out, param{name: n, typ: typ})
+		}
+	}
+	return out
+}
+
+func paramTypes(ps []param) string {
+	typs := make([]string, 0, len(ps))
+	for _, p := range ps {
+		typs = append(typs, p.typ)
+	}
+	return strings.Join(typs, ", ")
+}
+
+// key identifies the method by its receiver type and name, e.g. Service.Foo.
+func (m goMethod) key() string {
+	return receiverType(m.decl) + "." + m.decl.Name.Name
+}
+
+// mergeGoFile merges the methods of a freshly rendered Go file into an existing
+// one. Methods missing from the existing file are appended, the signatures of
+// methods whose parameters or results changed are updated and the bodies of
+// the existing methods are left untouched. Methods of the receiver types of
+// the rendered file that are only found in the existing file are reported as
+// orphaned, but kept.
+func mergeGoFile(existing, rendered []byte) ([]byte, mergeReport, error) {
+	var report mergeReport
+
+	fset := token.NewFileSet()
+	existingFile, err := parser.ParseFile(fset, "existing.go", existing, parser.ParseComments)
+	if err != nil {
+		return nil, report, fmt.Errorf("parsing existing file: %w", err)
+	}
+	renderedFile, err := parser.ParseFile(fset, "rendered.go", rendered, parser.ParseComments)
+	if err != nil {
+		return nil, report, fmt.Errorf("parsing rendered file: %w", err)
+	}
+
+	existingMethods := goMethods(existingFile)
+	renderedMethods := goMethods(renderedFile)
+
+	// edit replaces the bytes of the existing file between two offsets.
+	type edit struct {
+		start, end int
+		text       []byte
+	}
+	var (
+		edits     []edit
+		additions [][]byte
+		receivers []string
+	)
+
+	for _, m := range renderedMethods {
+		if !slices.Contains(receivers, receiverType(m.decl)) {
+			receivers = append(receivers, receiverType(m.decl))
+		}
+
+		i := slices.IndexFunc(existingMethods, func(v goMethod) bool { return v.key() == m.key() })
+		if i == -1 {
+			start := m.decl.Pos()
+			if m.decl.Doc != nil {
+				start = m.decl.Doc.Pos()
+			}
+			additions = append(additions, rendered[fset.Position(start).Offset:fset.Position(m.decl.End()).Offset])
+			report.Added = append(report.Added, m.key())
+			continue
+		}
+
+		current := existingMethods[i]
+		if current.signature == m.signature || current.decl.Body == nil || m.decl.Body == nil {
+			continue
+		}
+		edits = append(edits, edit{
+			start: fset.Position(current.decl.Pos()).Offset,
+			end:   fset.Position(current.decl.Body.Lbrace).Offset,
+			text:  mergedSignature(fset, rendered, m, current),
+		})
+		report.Changed = append(report.Changed, m.key())
+	}
+
+	for _, m := range existingMethods {
+		if !slices.Contains(receivers, receiverType(m.decl)) {
+			continue
+		}
+		if !slices.ContainsFunc(renderedMethods, func(v goMethod) bool { return v.key() == m.key() }) {
+			report.Orphaned = append(report.Orphaned, m.key())
+		}
+	}
+
+	// Apply the edits from the end of the file so the offsets of the
+	// remaining edits stay valid.
+	slices.SortFunc(edits, func(a, b edit) int { return b.start - a.start })
+	merged := slices.Clone(existing)
+	for _, e := range edits {
+		merged = slices.Concat(merged[:e.start], e.text, merged[e.end:])
+	}
+
+	for _, v := range additions {
+		merged = append(bytes.TrimRight(merged, "\n"), '\n', '\n')
+		merged = append(merged, v...)
+		merged = append(merged, '\n')
+	}
+
+	// The new signatures might reference packages the existing file doesn't
+	// import yet.
+	formatted, err := imports.Process("", merged, nil)
+	if err != nil {
+		return nil, report, fmt.Errorf("formatting merged file: %w", err)
+	}
+
+	return formatted, report, nil
+}
+
+// goMethods returns the methods declared in a file.
+func goMethods(file *ast.File) []goMethod {
+	var methods []goMethod
+	for _, d := range file.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+			continue
+		}
+
+		methods = append(methods, goMethod{
+			decl: fn,
+			signature: fmt.Sprintf(
+				"(%s) (%s) (%s)",
+				paramTypes(params(fn.Recv)),
+				paramTypes(params(fn.Type.Params)),
+				paramTypes(params(fn.Type.Results)),
+			),
+		})
+	}
+	return methods
+}
+
+// mergedSignature returns the signature of the rendered method, up to its
+// body, with the receiver and the parameters the existing method has at the
+// same position with the same type keeping their existing names. The existing
+// body refers to those names.
+func mergedSignature(fset *token.FileSet, rendered []byte, m, current goMethod) []byte {
+	type rename struct {
+		ident *ast.Ident
+		name  string
+	}
+
+	recv := params(m.decl.Recv)
+	in := params(m.decl.Type.Params)
+	all := slices.Concat(recv, in, params(m.decl.Type.Results))
+
+	// names are the names of the method once renamed, to avoid renaming a
+	// param to the name of another.
+	var names []string
+	for _, p := range all {
+		if p.name != nil {
+			names = append(names, p.name.Name)
+		}
+	}
+
+	var renames []rename
+	add := func(ps, existing []param, sameType bool) {
+		for i, p := range ps {
+			if p.name == nil || i >= len(existing) || existing[i].name == nil {
+				continue
+			}
+			name := existing[i].name.Name
+			if name == p.name.Name || name == "_" || (sameType && existing[i].typ != p.typ) || slices.Contains(names, name) {
+				continue
+			}
+			renames = append(renames, rename{ident: p.name, name: name})
+			names = append(names, name)
+		}
+	}
+	// The receiver keeps its name even if it changed from a value to a
+	// pointer.
+	add(recv, params(current.decl.Recv), false)
+	add(in, params(current.decl.Type.Params), true)
+
+	start := fset.Position(m.decl.Pos()).Offset
+	text := slices.Clone(rendered[start:fset.Position(m.decl.Body.Lbrace).Offset])
+
+	// Rename from the end so the offsets of the remaining idents stay valid.
+	slices.SortFunc(renames, func(a, b rename) int { return int(b.ident.Pos() - a.ident.Pos()) })
+	for _, r := range renames {
+		offset := fset.Position(r.ident.Pos()).Offset - start
+		text = slices.Concat(text[:offset], []byte(r.name), text[offset+len(r.ident.Name):])
+	}
+
+	return text
+}
+
+// receiverType returns the name of the receiver type of a method, without the
+// pointer or type parameters.
+func receiverType(fn *ast.FuncDecl) string {
+	expr := fn.Recv.List[0].Type
+	for {
+		switch v := expr.(type) {
+		case *ast.StarExpr:
+			expr = v.X
+		case *ast.IndexExpr:
+			expr = v.X
+		case *ast.IndexListExpr:
+			expr = v.X
+		case *ast.ParenExpr:
+			expr = v.X
+		case *ast.Ident:
+			return v.Name
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	version "github.com/jasonhancock/cobra-version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeGoFile(t *testing.T) {
+	existing := `package widgets
+
+import "context"
+
+type Service struct {
+	db string
+}
+
+// WidgetsGet gets a widget
+func (s *Service) WidgetsGet(ctx context.Context, id string) (Widget, error) {
+	return Widget{ID: id}, nil
+}
+
+// WidgetsDelete deletes a widget
+func (s *Service) WidgetsDelete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (s *Service) helper() string {
+	return s.db
+}
+`
+
+	rendered := `package widgets
+
+import (
+	"context"
+	"io"
+)
+
+type Service struct {
+	// TODO: add whatever you need to here.
+}
+
+// WidgetsDelete deletes a widget
+func (s *Service) WidgetsDelete(ctx context.Context, id string) error {
+	panic("not implemented")
+}
+
+// WidgetsGet gets a widget
+func (s *Service) WidgetsGet(ctx context.Context, id string, verbose bool) (Widget, error) {
+	panic("not implemented")
+}
+
+// WidgetsUpload uploads a widget
+func (s *Service) WidgetsUpload(ctx context.Context, body io.Reader) error {
+	panic("not implemented")
+}
+`
+
+	expected := `package widgets
+
+import (
+	"context"
+	"io"
+)
+
+type Service struct {
+	db string
+}
+
+// WidgetsGet gets a widget
+func (s *Service) WidgetsGet(ctx context.Context, id string, verbose bool) (Widget, error) {
+	return Widget{ID: id}, nil
+}
+
+// WidgetsDelete deletes a widget
+func (s *Service) WidgetsDelete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (s *Service) helper() string {
+	return s.db
+}
+
+// WidgetsUpload uploads a widget
+func (s *Service) WidgetsUpload(ctx context.Context, body io.Reader) error {
+	panic("not implemented")
+}
+`
+
+	merged, report, err := mergeGoFile([]byte(existing), []byte(rendered))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(merged))
+	require.Equal(t, mergeReport{
+		Added:    []string{"Service.WidgetsUpload"},
+		Changed:  []string{"Service.WidgetsGet"},
+		Orphaned: []string{"Service.helper"},
+	}, report)
+
+	// Merging again is a no-op.
+	again, report, err := mergeGoFile(merged, []byte(rendered))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(again))
+	require.Equal(t, mergeReport{Orphaned: []string{"Service.helper"}}, report)
+}
+
+func TestMergeGoFileWrappedSignature(t *testing.T) {
+	existing := `package widgets
+
+import "context"
+
+func (s *Service) WidgetsDelete(
+	ctx context.Context,
+	id string,
+) error {
+	return nil
+}
+`
+
+	rendered := `package widgets
+
+func (s *Service) WidgetsDelete(ctx context.Context, id string) error {
+	panic("not implemented")
+}
+`
+
+	merged, report, err := mergeGoFile([]byte(existing), []byte(rendered))
+	require.NoError(t, err)
+	require.Equal(t, existing, string(merged))
+	require.Equal(t, mergeReport{}, report)
+}
+
+func TestMergeGoFileRenamed(t *testing.T) {
+	existing := `package widgets
+
+import "context"
+
+func (svc *Service) WidgetsDelete(c context.Context, widgetID string) error {
+	return svc.db.Delete(c, widgetID)
+}
+
+func (svc *Service) WidgetsGet(c context.Context, widgetID string) (Widget, error) {
+	return svc.db.Get(c, widgetID)
+}
+`
+
+	rendered := `package widgets
+
+import "context"
+
+func (s *Service) WidgetsDelete(ctx context.Context, id string) error {
+	panic("not implemented")
+}
+
+func (s *Service) WidgetsGet(ctx context.Context, id string, verbose bool) (Widget, error) {
+	panic("not implemented")
+}
+`
+
+	// Renamed parameters aren't a change. When the types change, the
+	// receiver and the matching parameters keep the names the body uses.
+	expected := `package widgets
+
+import "context"
+
+func (svc *Service) WidgetsDelete(c context.Context, widgetID string) error {
+	return svc.db.Delete(c, widgetID)
+}
+
+func (svc *Service) WidgetsGet(c context.Context, widgetID string, verbose bool) (Widget, error) {
+	return svc.db.Get(c, widgetID)
+}
+`
+
+	merged, report, err := mergeGoFile([]byte(existing), []byte(rendered))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(merged))
+	require.Equal(t, mergeReport{Changed: []string{"Service.WidgetsGet"}}, report)
+}
+
+func TestRunTemplateMerge(t *testing.T) {
+	info := version.Info{Version: "1.2.3"}
+	outfile := filepath.Join(t.TempDir(), "service.go")
+	opts := cmdOptions{language: "go", merge: true}
+
+	require.NoError(t, runTemplate("widgets", "service", outfile, opts, info, "testdata/openapi_base.yaml", "testdata/cases/methods/openapi.yaml"))
+
+	b, err := os.ReadFile(outfile)
+	require.NoError(t, err)
+	edited := strings.Replace(string(b), `panic("not implemented")`, `return nil`, 1)
+	require.NoError(t, os.WriteFile(outfile, []byte(edited), 0644))
+
+	// The all case has none of the methods of the methods case.
+	require.NoError(t, runTemplate("widgets", "service", outfile, opts, info, "testdata/openapi_base.yaml", "testdata/cases/all/openapi.yaml"))
+
+	b, err = os.ReadFile(outfile)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "return nil")
+	require.Contains(t, string(b), "func (s *Service) WidgetsExists(")
+
+	opts.check = true
+	require.NoError(t, runTemplate("widgets", "service", outfile, opts, info, "testdata/openapi_base.yaml", "testdata/cases/all/openapi.yaml"))
+
+	opts = cmdOptions{language: "js", merge: true}
+	require.ErrorContains(t, runTemplate("widgets", "client", outfile, opts, info, "testdata/openapi_base.yaml", "testdata/cases/all/openapi.yaml"), "only supported for go")
+}
@@ -0,0 +1,207 @@
+package template
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// nonNullTypes returns the types of a schema other than null. In 3.1 a schema
+// can have several types, e.g. [string, "null"].
+func nonNullTypes(s *base.Schema) []string {
+	return slices.DeleteFunc(slices.Clone(s.Type), func(t string) bool { return t == "null" })
+}
+
+// isNullSchema reports whether a schema only allows null.
+func isNullSchema(s *base.Schema) bool {
+	return s != nil && len(s.Type) == 1 && s.Type[0] == "null"
+}
+
+// isNullable reports whether a schema allows null, either with the 3.0
+// nullable keyword, a null type in 3.1 or an anyOf with a null schema.
+func isNullable(s *base.Schema) bool {
+	if s == nil {
+		return false
+	}
+	if fromBoolPtr(s.Nullable) || slices.Contains(s.Type, "null") {
+		return true
+	}
+	return slices.ContainsFunc(s.AnyOf, func(sp *base.SchemaProxy) bool { return isNullSchema(sp.Schema()) })
+}
+
+// unionTypeOrder is the order the members of a union are tried in when
+// decoding. Integers come before numbers since every integer is a number.
+var unionTypeOrder = []string{"boolean", "integer", "number", "string"}
+
+// UnionModelType is the type of a schema with several types, e.g.
+// [integer, string]. A struct is generated for each distinct set of types,
+// holding one pointer per type.
+type UnionModelType struct {
+	Members []ModelType
+}
+
+// newUnionModelType returns the type of a schema with several types. Only
+// unions of booleans, integers, numbers and strings have a generated type;
+// the others are typed as any.
+func newUnionModelType(schema *base.SchemaProxy, types []string) (ModelType, error) {
+	for _, t := range types {
+		if !slices.Contains(unionTypeOrder, t) {
+			return newPrimitiveModelType("any"), nil
+		}
+	}
+
+	var u UnionModelType
+	for _, t := range unionTypeOrder {
+		if !slices.Contains(types, t) {
+			continue
+		}
+		mt, err := scalarModelType(schema, t)
+		if err != nil {
+			return nil, err
+		}
+		u.Members = append(u.Members, mt)
+	}
+
+	return &u, nil
+}
+
+func (u *UnionModelType) Type() string {
+	names := make([]string, 0, len(u.Members))
+	for _, v := range u.Variants() {
+		names = append(names, v.Name)
+	}
+	return strings.Join(names, "Or")
+}
+
+func (u *UnionModelType) Imports() []Import {
+	var imports []Import
+	for _, v := range u.Members {
+		imports = append(imports, v.Imports()...)
+	}
+	return imports
+}
+
+// UnionVariant is one of the types of a union.
+type UnionVariant struct {
+	// Name is the name of the field of the union holding the value, e.g.
+	// Int64 or Time.
+	Name string
+	Type string
+}
+
+func (u *UnionModelType) Variants() []UnionVariant {
+	variants := make([]UnionVariant, 0, len(u.Members))
+	for _, v := range u.Members {
+		t := v.Type()
+		name := t[strings.LastIndex(t, ".")+1:]
+		variants = append(variants, UnionVariant{
+			Name: strings.ToUpper(name[:1]) + name[1:],
+			Type: t,
+		})
+	}
+	return variants
+}
+
+// unionOf returns the union a field of the given type holds, if any.
+func unionOf(mt ModelType) *UnionModelType {
+	switch typed := mt.(type) {
+	case *UnionModelType:
+		return typed
+	case *SliceModelType:
+		return unionOf(typed.Items)
+	case *MapModelType:
+		return unionOf(typed.Items)
+	default:
+		return nil
+	}
+}
+
+// operationUnions returns the unions used by the request and response bodies
+// of an operation, including the items of streamed responses.
+func operationUnions(op *v3high.Operation) ([]*UnionModelType, error) {
+	var unions []*UnionModelType
+	add := func(content *orderedmap.Map[string, *v3high.MediaType], mediaTypes ...string) error {
+		if content == nil {
+			return nil
+		}
+		for _, v := range mediaTypes {
+			media, ok := content.Get(v)
+			if !ok {
+				continue
+			}
+			for _, sp := range []*base.SchemaProxy{media.Schema, media.ItemSchema} {
+				if sp == nil {
+					continue
+				}
+				mt, err := modelType(sp)
+				if err != nil {
+					return err
+				}
+				if u := unionOf(mt); u != nil {
+					unions = append(unions, u)
+				}
+			}
+		}
+		return nil
+	}
+
+	if op.RequestBody != nil {
+		if err := add(op.RequestBody.Content, "application/json"); err != nil {
+			return nil, err
+		}
+	}
+	if op.Responses != nil && op.Responses.Codes != nil {
+		for r := range op.Responses.Codes.ValuesFromOldest() {
+			if err := add(r.Content, append([]string{"application/json"}, streamMediaTypes...)...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return unions, nil
+}
+
+// Unions returns the unions used by the models, handlers, callbacks and
+// webhooks, sorted by name.
+func (d TemplateData) Unions() []*UnionModelType {
+	unions := d.Models.Unions()
+	for _, h := range d.Handlers {
+		unions = append(unions, h.unions...)
+	}
+	for _, c := range d.Callbacks {
+		unions = append(unions, c.unions...)
+	}
+	for _, w := range d.Webhooks {
+		unions = append(unions, w.unions...)
+	}
+	return uniqueUnions(unions)
+}
+
+// uniqueUnions returns the distinct unions, sorted by name.
+func uniqueUnions(unions []*UnionModelType) []*UnionModelType {
+	var unique []*UnionModelType
+	for _, u := range unions {
+		if !slices.ContainsFunc(unique, func(v *UnionModelType) bool { return v.Type() == u.Type() }) {
+			unique = append(unique, u)
+		}
+	}
+
+	slices.SortFunc(unique, func(a, b *UnionModelType) int { return strings.Compare(a.Type(), b.Type()) })
+	return unique
+}
+
+// Unions returns the unions used by the fields of the models, sorted by name.
+func (m Models) Unions() []*UnionModelType {
+	var unions []*UnionModelType
+	for _, mod := range m {
+		for _, f := range mod.Fields {
+			if f.union != nil {
+				unions = append(unions, f.union)
+			}
+		}
+	}
+	return uniqueUnions(unions)
+}
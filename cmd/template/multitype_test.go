@@ -0,0 +1,78 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestIsNullable(t *testing.T) {
+	yes := true
+	null := base.CreateSchemaProxy(&base.Schema{Type: []string{"null"}})
+	str := base.CreateSchemaProxy(&base.Schema{Type: []string{"string"}})
+
+	tests := []struct {
+		desc     string
+		schema   *base.Schema
+		expected bool
+	}{
+		{"not nullable", &base.Schema{Type: []string{"string"}}, false},
+		{"3.0 nullable", &base.Schema{Type: []string{"string"}, Nullable: &yes}, true},
+		{"3.1 type array", &base.Schema{Type: []string{"string", "null"}}, true},
+		{"any of", &base.Schema{AnyOf: []*base.SchemaProxy{str, null}}, true},
+		{"any of without null", &base.Schema{AnyOf: []*base.SchemaProxy{str}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.expected, isNullable(tt.schema))
+		})
+	}
+}
+
+func TestUnionModelType(t *testing.T) {
+	tests := []struct {
+		desc     string
+		types    []string
+		format   string
+		expected string
+	}{
+		{"sorted", []string{"integer", "string"}, "", "Int64OrString"},
+		{"unsorted", []string{"string", "boolean", "integer"}, "", "BoolOrInt64OrString"},
+		{"format", []string{"number", "string"}, "date-time", "Float64OrTime"},
+		{"object", []string{"object", "string"}, "", "any"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			sp := base.CreateSchemaProxy(&base.Schema{
+				Type:       tt.types,
+				Format:     tt.format,
+				Extensions: orderedmap.New[string, *yaml.Node](),
+			})
+			mt, err := modelType(sp)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, mt.Type())
+		})
+	}
+}
+
+func TestGetParamsUnion(t *testing.T) {
+	op := &v3high.Operation{
+		Parameters: []*v3high.Parameter{
+			{
+				Name:       "size",
+				In:         "query",
+				Schema:     base.CreateSchemaProxy(&base.Schema{Type: []string{"integer", "string"}, Extensions: orderedmap.New[string, *yaml.Node]()}),
+				Extensions: orderedmap.New[string, *yaml.Node](),
+			},
+		},
+	}
+
+	_, err := getParams(op)
+	require.EqualError(t, err, "parameter size: parameters of several types, like Int64OrString, aren't supported")
+}
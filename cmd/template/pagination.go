@@ -0,0 +1,179 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+const extensionPagination = "x-pagination"
+
+const (
+	paginationStyleCursor = "cursor"
+	paginationStyleOffset = "offset"
+	paginationStyleLink   = "link"
+)
+
+// paginationExtension is the value of the x-pagination extension on an
+// operation.
+type paginationExtension struct {
+	// Style is one of cursor, offset or link.
+	Style string `yaml:"style"`
+
+	// Items is the name of the property in the response holding the page's items.
+	Items string `yaml:"items"`
+
+	// CursorParam is the query parameter the cursor is sent in (cursor style).
+	CursorParam string `yaml:"cursorParam"`
+
+	// NextCursor is the property in the response holding the cursor of the next
+	// page (cursor style).
+	NextCursor string `yaml:"nextCursor"`
+
+	// OffsetParam is the query parameter the offset is sent in (offset style).
+	OffsetParam string `yaml:"offsetParam"`
+}
+
+// Pagination describes how to walk the pages of a list operation.
+type Pagination struct {
+	Style      string
+	ItemType   string
+	ItemsField string
+
+	CursorField       string
+	CursorPointer     bool
+	CursorType        string
+	NextCursorField   string
+	NextCursorPointer bool
+
+	OffsetField   string
+	OffsetPointer bool
+	OffsetType    string
+}
+
+func getPagination(op *v3high.Operation) (*paginationExtension, error) {
+	node, ok := op.Extensions.Get(extensionPagination)
+	if !ok {
+		return nil, nil
+	}
+
+	var ext paginationExtension
+	if err := node.Decode(&ext); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", extensionPagination, err)
+	}
+
+	return &ext, nil
+}
+
+// buildPagination resolves the x-pagination extension of a handler against its
+// params and response model.
+func buildPagination(h Handler, ext *paginationExtension, models Models) (*Pagination, error) {
+	if h.IsFileDownload || h.IsStream() || h.ResponseType == "" || h.ResponseType == "[]byte" {
+		return nil, errors.New("pagination requires a JSON response")
+	}
+
+	model, ok := models.find(h.ResponseType)
+	if !ok {
+		return nil, fmt.Errorf("response model %q not found", h.ResponseType)
+	}
+
+	if ext.Items == "" {
+		return nil, errors.New("items not set")
+	}
+	items, ok := model.fieldByTag(ext.Items)
+	if !ok || !strings.HasPrefix(items.Type, "[]") {
+		return nil, fmt.Errorf("response model %q has no array property %q", model.Name, ext.Items)
+	}
+
+	p := Pagination{
+		Style:      ext.Style,
+		ItemType:   strings.TrimPrefix(items.Type, "[]"),
+		ItemsField: items.Name,
+	}
+
+	switch ext.Style {
+	case paginationStyleCursor:
+		param, ok := h.Params.queryParam(ext.CursorParam)
+		if !ok {
+			return nil, fmt.Errorf("cursor query parameter %q not found", ext.CursorParam)
+		}
+		next, ok := model.fieldByTag(ext.NextCursor)
+		if !ok {
+			return nil, fmt.Errorf("response model %q has no property %q", model.Name, ext.NextCursor)
+		}
+		if param.Type != next.Type {
+			return nil, fmt.Errorf("cursor query parameter type %q does not match next cursor type %q", param.Type, next.Type)
+		}
+
+		// The last page has the zero value of the cursor type as its next
+		// cursor.
+		p.CursorField = typeName(param.Name)
+		p.CursorPointer = !param.Required
+		p.CursorType = param.Type
+		p.NextCursorField = next.Name
+		p.NextCursorPointer = next.Pointer()
+	case paginationStyleOffset:
+		param, ok := h.Params.queryParam(ext.OffsetParam)
+		if !ok {
+			return nil, fmt.Errorf("offset query parameter %q not found", ext.OffsetParam)
+		}
+		switch param.Type {
+		case "int8", "int16", "int32", "int64":
+		default:
+			return nil, fmt.Errorf("offset query parameter must be an integer, got %q", param.Type)
+		}
+
+		p.OffsetField = typeName(param.Name)
+		p.OffsetPointer = !param.Required
+		p.OffsetType = param.Type
+	case paginationStyleLink:
+	default:
+		return nil, fmt.Errorf("unsupported style %q", ext.Style)
+	}
+
+	return &p, nil
+}
+
+func (m Models) find(name string) (Model, bool) {
+	for _, v := range m {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Model{}, false
+}
+
+func (m Model) fieldByTag(tag string) (Field, bool) {
+	for _, v := range m.Fields {
+		if v.StructTag == tag {
+			return v, true
+		}
+	}
+	return Field{}, false
+}
+
+// Pointer reports whether the field is rendered as a pointer.
+func (f Field) Pointer() bool {
+	return (!f.Required || f.Nullable) && !f.NoPointer
+}
+
+func (p Params) queryParam(name string) (Param, bool) {
+	for _, v := range p {
+		if v.Location == "query" && v.Name == name {
+			return v, true
+		}
+	}
+	return Param{}, false
+}
+
+// HasLinkPagination reports whether any handler follows Link headers to page.
+func (t TemplateData) HasLinkPagination() bool {
+	for _, h := range t.Handlers {
+		if h.Pagination != nil && h.Pagination.Style == paginationStyleLink {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPagination(t *testing.T) {
+	models := Models{
+		{
+			Name: "ListResponse",
+			Fields: []Field{
+				{Name: "Items", Type: "[]Widget", StructTag: "items", Required: true, NoPointer: true},
+				{Name: "NextCursor", Type: "string", StructTag: "next_cursor"},
+				{Name: "NextAfter", Type: "int64", StructTag: "next_after", Required: true},
+				{Name: "Total", Type: "int64", StructTag: "total", Required: true},
+			},
+		},
+	}
+
+	params := Params{
+		{Name: "cursor", Type: "string", Location: "query"},
+		{Name: "after", Type: "int64", Location: "query"},
+		{Name: "offset", Type: "int32", Location: "query", Required: true},
+		{Name: "name", Type: "string", Location: "query"},
+	}
+
+	h := Handler{
+		Name:         "list",
+		ResponseType: "ListResponse",
+		Params:       params,
+	}
+
+	tests := []struct {
+		desc     string
+		handler  Handler
+		ext      paginationExtension
+		expected *Pagination
+		err      string
+	}{
+		{
+			"cursor",
+			h,
+			paginationExtension{Style: "cursor", Items: "items", CursorParam: "cursor", NextCursor: "next_cursor"},
+			&Pagination{
+				Style:             "cursor",
+				ItemType:          "Widget",
+				ItemsField:        "Items",
+				CursorField:       "Cursor",
+				CursorPointer:     true,
+				CursorType:        "string",
+				NextCursorField:   "NextCursor",
+				NextCursorPointer: true,
+			},
+			"",
+		},
+		{
+			"integer cursor",
+			h,
+			paginationExtension{Style: "cursor", Items: "items", CursorParam: "after", NextCursor: "next_after"},
+			&Pagination{
+				Style:           "cursor",
+				ItemType:        "Widget",
+				ItemsField:      "Items",
+				CursorField:     "After",
+				CursorPointer:   true,
+				CursorType:      "int64",
+				NextCursorField: "NextAfter",
+			},
+			"",
+		},
+		{
+			"offset",
+			h,
+			paginationExtension{Style: "offset", Items: "items", OffsetParam: "offset"},
+			&Pagination{
+				Style:       "offset",
+				ItemType:    "Widget",
+				ItemsField:  "Items",
+				OffsetField: "Offset",
+				OffsetType:  "int32",
+			},
+			"",
+		},
+		{
+			"link",
+			h,
+			paginationExtension{Style: "link", Items: "items"},
+			&Pagination{Style: "link", ItemType: "Widget", ItemsField: "Items"},
+			"",
+		},
+		{
+			"items not an array",
+			h,
+			paginationExtension{Style: "link", Items: "total"},
+			nil,
+			`response model "ListResponse" has no array property "total"`,
+		},
+		{
+			"missing cursor param",
+			h,
+			paginationExtension{Style: "cursor", Items: "items", CursorParam: "page", NextCursor: "next_cursor"},
+			nil,
+			`cursor query parameter "page" not found`,
+		},
+		{
+			"cursor type mismatch",
+			h,
+			paginationExtension{Style: "cursor", Items: "items", CursorParam: "offset", NextCursor: "next_cursor"},
+			nil,
+			`cursor query parameter type "int32" does not match next cursor type "string"`,
+		},
+		{
+			"offset not an integer",
+			h,
+			paginationExtension{Style: "offset", Items: "items", OffsetParam: "name"},
+			nil,
+			`offset query parameter must be an integer, got "string"`,
+		},
+		{
+			"unknown style",
+			h,
+			paginationExtension{Style: "pages", Items: "items"},
+			nil,
+			`unsupported style "pages"`,
+		},
+		{
+			"file download",
+			Handler{Name: "download", ResponseType: "*FileDownloadResponse", IsFileDownload: true},
+			paginationExtension{Style: "link", Items: "items"},
+			nil,
+			"pagination requires a JSON response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			result, err := buildPagination(tt.handler, &tt.ext, models)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -3,7 +3,6 @@ package template
 import (
 	"bytes"
 	_ "embed"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,8 +11,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/Masterminds/sprig/v3"
 	version "github.com/jasonhancock/cobra-version"
@@ -42,27 +43,14 @@ const (
 	extensionGoPropertyNames  = "x-go-property-names"
 	extensionGoDoNotSerialize = "x-go-do-not-serialize"
 	extensionRetrievalName    = "x-retrieval-name"
+	extensionSensitive        = "x-sensitive"
+	extensionIdempotent       = "x-idempotent"
 )
 
 type generatorInfo struct {
-	Name    string
-	Version string
-}
-
-type Security struct {
-	Name    string
-	NumArgs int
-
-	ArgPermutations []argPermutation
-}
-
-type argPermutation struct {
-	Hash string
-	Args []string
-}
-
-func (a argPermutation) ArgStr() string {
-	return quotedStrings(a.Args...)
+	Name        string
+	Version     string
+	Fingerprint fingerprint
 }
 
 func quotedStrings(strs ...string) string {
@@ -159,45 +147,6 @@ func formatComment(input string) string {
 	return strings.Join(lines, "\n")
 }
 
-func (s *Security) AuthzArgs() string {
-	return "args ...string"
-}
-
-func (s *Security) AddArgPermutation(args []string) {
-	b, _ := json.Marshal(args)
-	perm := helpers.MD5(b)
-
-	for _, v := range s.ArgPermutations {
-		if v.Hash == perm {
-			return
-		}
-	}
-
-	s.ArgPermutations = append(s.ArgPermutations, argPermutation{
-		Hash: perm,
-		Args: args,
-	})
-
-	sort.Slice(s.ArgPermutations, func(i, j int) bool {
-		fromI := strings.Join(s.ArgPermutations[i].Args, "|")
-		fromJ := strings.Join(s.ArgPermutations[j].Args, "|")
-		return fromI < fromJ
-	})
-}
-
-func (s *Security) GetPermutationIndex(args []string) (int, error) {
-	b, _ := json.Marshal(args)
-	perm := helpers.MD5(b)
-
-	for i := range s.ArgPermutations {
-		if s.ArgPermutations[i].Hash == perm {
-			return i, nil
-		}
-	}
-
-	return -1, fmt.Errorf("argument permutation %q not found", strings.Join(args, ", "))
-}
-
 func templateDataFrom(
 	input *libopenapi.DocumentModel[v3high.Document],
 	packageName string,
@@ -215,7 +164,16 @@ func templateDataFrom(
 		Language: opts.language,
 	}
 
-	discoveredSecurity := make(map[string]*Security)
+	spec, err := input.Model.Render()
+	if err != nil {
+		return TemplateData{}, fmt.Errorf("rendering spec: %w", err)
+	}
+	data.Spec = string(spec)
+
+	data.SecuritySchemes, err = getSecuritySchemes(&input.Model)
+	if err != nil {
+		return TemplateData{}, err
+	}
 
 	if input.Model.Paths != nil {
 		for pair := input.Model.Paths.PathItems.First(); pair != nil; pair = pair.Next() {
@@ -225,82 +183,29 @@ func templateDataFrom(
 				k := opPair.Key()
 				op := opPair.Value()
 
-				h := Handler{
-					Name:               op.OperationId,
-					op:                 op,
-					Path:               path,
-					Method:             k,
-					SuccessStatusCode:  getStatusCode(op.Responses),
-					SuccessContentType: getSuccessContentType(op.Responses),
-					PkgModels:          opts.pkgModels,
-					IsFileDownload:     getIsFileDownload(op.Responses),
+				h, err := buildHandler(op.OperationId, path, k, op, opts)
+				if err != nil {
+					return TemplateData{}, err
 				}
 
 				if h.IsFileDownload {
 					data.HasFileDownloads = true
-					h.ResponseType = "*FileDownloadResponse"
 				}
 
-				var err error
-				h.Params, err = getParams(op)
-				if err != nil {
-					return TemplateData{}, fmt.Errorf("getting parameters %s: %w", op.OperationId, err)
-				}
+				h.SecurityRequirements = getSecurityRequirements(op, &input.Model)
 
-				h.ErrorResponseTypes, err = getErrorResponses(op)
-				if err != nil {
-					return TemplateData{}, fmt.Errorf("getting error responses %s: %w", op.OperationId, err)
-				}
-
-				h.ResponseType, err = getResponseType(op)
-				if err != nil {
-					return TemplateData{}, fmt.Errorf("getting response type %s: %w", op.OperationId, err)
+				if opts.groupByTag && len(op.Tags) > 0 {
+					h.Group = op.Tags[0]
 				}
 
-				h.RequestBodyType, err = getRequestBodyType(op)
+				callbacks, err := getCallbacks(h, op, opts)
 				if err != nil {
-					return TemplateData{}, fmt.Errorf("getting request body type %s: %w", op.OperationId, err)
+					return TemplateData{}, fmt.Errorf("getting callbacks %s: %w", op.OperationId, err)
 				}
-
-				if len(op.Security) > 0 {
-					if len(op.Security) > 1 {
-						return TemplateData{}, errors.New("more than one security thing not supported yet")
-					}
-					for _, v := range op.Security {
-						if v.Requirements.Len() > 1 {
-							return TemplateData{}, errors.New("more than one security requirements not supported yet")
-						}
-
-						for secPair := v.Requirements.First(); secPair != nil; secPair = secPair.Next() {
-							secName := secPair.Key()
-							secArgs := secPair.Value()
-							if _, ok := discoveredSecurity[secName]; !ok {
-								discoveredSecurity[secName] = &Security{
-									Name:    secName,
-									NumArgs: len(secArgs),
-								}
-							}
-
-							sec := discoveredSecurity[secName]
-
-							if sec.NumArgs == 0 && len(secArgs) != 0 {
-								discoveredSecurity[secName].NumArgs = len(secArgs)
-							} else if sec.NumArgs != len(secArgs) && len(secArgs) != 0 {
-								return TemplateData{},
-									fmt.Errorf(
-										"inconsistent number of arguments for security %q. expected=%d actual=%d",
-										secName,
-										sec.NumArgs,
-										len(secArgs),
-									)
-							}
-							//if len(secArgs) > 0 {
-							sec.AddArgPermutation(secArgs)
-							//}
-
-							h.Security = sec
-							h.SecurityArgs = secArgs
-						}
+				for _, cb := range callbacks {
+					data.Callbacks = append(data.Callbacks, cb)
+					if cb.Params.HasParams() {
+						data.Models = append(data.Models, cb.Params.buildQueryParamsModel(cb.Name))
 					}
 				}
 
@@ -313,6 +218,19 @@ func templateDataFrom(
 		}
 	}
 
+	if err := resolveSecurityScopes(data.SecuritySchemes, data.Handlers); err != nil {
+		return TemplateData{}, err
+	}
+
+	if err := checkServiceGroups(data.Handlers); err != nil {
+		return TemplateData{}, err
+	}
+
+	data.Webhooks, err = getWebhooks(&input.Model)
+	if err != nil {
+		return TemplateData{}, err
+	}
+
 	if input.Model.Components != nil {
 		for pair := input.Model.Components.Schemas.First(); pair != nil; pair = pair.Next() {
 			name := pair.Key()
@@ -323,22 +241,122 @@ func templateDataFrom(
 				return TemplateData{}, fmt.Errorf("%s: %w", name, err)
 			}
 
-			data.Models = append(data.Models, model)
+			data.Models = append(data.Models, visibilityVariants(model, val.Schema())...)
+		}
+	}
+
+	if err := checkModelNames(data.Models); err != nil {
+		return TemplateData{}, err
+	}
+
+	// Pagination is resolved once all of the models are known.
+	for i, h := range data.Handlers {
+		if h.pagination == nil {
+			continue
+		}
+
+		var err error
+		data.Handlers[i].Pagination, err = buildPagination(h, h.pagination, data.Models)
+		if err != nil {
+			return TemplateData{}, fmt.Errorf("%s %s: %w", extensionPagination, h.Name, err)
 		}
 	}
 
-	data.Security = make([]Security, 0, len(discoveredSecurity))
-	for _, v := range discoveredSecurity {
-		data.Security = append(data.Security, *v)
+	// Callback URLs are resolved once all of the models are known.
+	for i, c := range data.Callbacks {
+		var err error
+		data.Callbacks[i].URLFormat, data.Callbacks[i].URLValues, err = buildCallbackURL(c, data.Models)
+		if err != nil {
+			return TemplateData{}, fmt.Errorf("callback %s: %w", c.Name, err)
+		}
 	}
 
 	sort.Slice(data.Handlers, func(i, j int) bool { return data.Handlers[i].ExportedName() < data.Handlers[j].ExportedName() })
+	sort.Slice(data.Callbacks, func(i, j int) bool { return data.Callbacks[i].ExportedName() < data.Callbacks[j].ExportedName() })
+	sort.Slice(data.Webhooks, func(i, j int) bool { return data.Webhooks[i].ExportedName() < data.Webhooks[j].ExportedName() })
 	sort.Slice(data.Models, func(i, j int) bool { return data.Models[i].Name < data.Models[j].Name })
-	sort.Slice(data.Security, func(i, j int) bool { return data.Security[i].Name < data.Security[j].Name })
 
 	return data, nil
 }
 
+// buildHandler builds the Handler for an operation.
+func buildHandler(name, path, method string, op *v3high.Operation, opts cmdOptions) (Handler, error) {
+	h := Handler{
+		Name:               name,
+		op:                 op,
+		Path:               path,
+		Method:             method,
+		SuccessStatusCode:  getStatusCode(op.Responses),
+		SuccessContentType: getSuccessContentType(op.Responses),
+		PkgModels:          opts.pkgModels,
+		IsFileDownload:     getIsFileDownload(op.Responses),
+	}
+
+	if h.IsFileDownload {
+		h.ResponseType = "*FileDownloadResponse"
+	}
+
+	var err error
+	h.Params, err = getParams(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting parameters %s: %w", name, err)
+	}
+
+	h.ErrorResponseTypes, err = getErrorResponses(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting error responses %s: %w", name, err)
+	}
+
+	h.ResponseType, err = getResponseType(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting response type %s: %w", name, err)
+	}
+
+	h.Responses = getDeclaredResponses(op.Responses)
+
+	h.pagination, err = getPagination(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting pagination %s: %w", name, err)
+	}
+
+	h.StreamContentType, h.StreamItemType, err = getStream(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting stream %s: %w", name, err)
+	}
+
+	h.RequestBodyType, err = getRequestBodyType(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting request body type %s: %w", name, err)
+	}
+
+	h.unions, err = operationUnions(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting unions %s: %w", name, err)
+	}
+
+	h.Idempotent, err = getExtensionBool(op.Extensions, extensionIdempotent)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting %s %s: %w", extensionIdempotent, name, err)
+	}
+
+	h.Retry, err = getRetry(op, method, h.Idempotent)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting retry policy %s: %w", name, err)
+	}
+
+	h.Timeout, err = getTimeout(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting timeout %s: %w", name, err)
+	}
+
+	h.Deprecated, h.Sunset, err = getDeprecation(op)
+	if err != nil {
+		return Handler{}, fmt.Errorf("getting deprecation %s: %w", name, err)
+	}
+
+	return h, nil
+}
+
 func buildFieldNameMappings(s *base.Schema, data map[string]string) (map[string]string, error) {
 	local := make(map[string]string)
 	if fieldNames, ok := s.Extensions.Get(extensionGoPropertyNames); ok {
@@ -398,12 +416,24 @@ func buildFields(schema *base.Schema) ([]Field, []Import, error) {
 		case *MapModelType:
 			noPointer = true
 			imports = append(imports, typed.Imports()...)
+		case *UnionModelType:
+			// A union is null when none of its members is set.
+			noPointer = true
+		case *BasicModelType:
+			noPointer = *typed == "any"
 		default:
 		}
 
+		var (
+			requestType string
+			union       *UnionModelType
+		)
 		if goType != "" {
 			dataType = goType
 			imports = append(imports, goImport)
+		} else {
+			requestType = requestModelType(v)
+			union = unionOf(mt)
 		}
 
 		_, req := required[fieldName]
@@ -418,14 +448,24 @@ func buildFields(schema *base.Schema) ([]Field, []Import, error) {
 			return nil, nil, err
 		}
 
-		fields = append(fields, Field{
+		f := Field{
 			Name:           typeName(typeNameStr),
 			Type:           dataType,
 			StructTag:      fieldName,
 			Required:       req,
 			NoPointer:      noPointer,
 			DoNotSerialize: doNotSerialize,
-		})
+			Nullable:       isNullable(v.Schema()),
+			union:          union,
+			ReadOnly:       fromBoolPtr(v.Schema().ReadOnly),
+			WriteOnly:      fromBoolPtr(v.Schema().WriteOnly),
+			requestType:    requestType,
+		}
+		if err := setFieldDoc(&f, v); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", fieldName, err)
+		}
+
+		fields = append(fields, f)
 	}
 
 	for _, v := range schema.AllOf {
@@ -448,9 +488,14 @@ func getModel(name string, s *base.SchemaProxy) (Model, error) {
 		Description: schema.Description,
 	}
 
-	if len(schema.Enum) > 0 && len(schema.Type) == 1 && schema.Type[0] == "string" {
+	if types := nonNullTypes(schema); len(schema.Enum) > 0 && len(types) == 1 && types[0] == "string" {
 		m.Enumerated = true
 		for _, yn := range schema.Enum {
+			if yn.Tag == "!!null" {
+				// A nullable enum lists null among its values.
+				continue
+			}
+
 			var str string
 			if err := yn.Decode(&str); err != nil {
 				return Model{}, fmt.Errorf("decoding enum value into string: %w", err)
@@ -534,6 +579,20 @@ func getDoNotSerialize(extensions *orderedmap.Map[string, *yaml.Node]) (bool, er
 	return sBool, nil
 }
 
+func getExtensionBool(extensions *orderedmap.Map[string, *yaml.Node], key string) (bool, error) {
+	b, ok := extensions.Get(key)
+	if !ok {
+		return false, nil
+	}
+
+	var bVal bool
+	if err := b.Decode(&bVal); err != nil {
+		return false, fmt.Errorf("%s was set, but not to a boolean value: %w", key, err)
+	}
+
+	return bVal, nil
+}
+
 func getExtensionString(extensions *orderedmap.Map[string, *yaml.Node], key string) (string, error) {
 	t, ok := extensions.Get(key)
 	if !ok {
@@ -588,16 +647,32 @@ func getParams(op *v3high.Operation) ([]Param, error) {
 		if err != nil {
 			return nil, err
 		}
+		if u := unionOf(mt); u != nil {
+			return nil, fmt.Errorf("parameter %s: parameters of several types, like %s, aren't supported", v.Name, u.Type())
+		}
 
 		p := Param{
-			Name:     v.Name,
-			Type:     mt.Type(),
-			Location: v.In,
-			Required: fromBoolPtr(v.Required),
+			Name:       v.Name,
+			Type:       mt.Type(),
+			Location:   v.In,
+			Required:   fromBoolPtr(v.Required),
+			Deprecated: v.Deprecated,
 		}
 
 		p.RetrievalName, _ = getExtensionString(v.Extensions, extensionRetrievalName)
 
+		// The parameter is sensitive if either the parameter or its schema is marked.
+		p.Sensitive, err = getExtensionBool(v.Extensions, extensionSensitive)
+		if err != nil {
+			return nil, err
+		}
+		if !p.Sensitive && v.Schema != nil {
+			p.Sensitive, err = getExtensionBool(v.Schema.Schema().Extensions, extensionSensitive)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		// Handle enum values
 		if p.Type == "string" {
 			for _, yn := range v.Schema.Schema().Enum {
@@ -699,8 +774,9 @@ func modelType(schema *base.SchemaProxy) (ModelType, error) {
 	}
 
 	sch := schema.Schema()
+	types := nonNullTypes(sch)
 
-	if len(sch.Type) == 0 {
+	if len(types) == 0 {
 		if len(sch.AnyOf) > 0 {
 			if len(sch.AnyOf) != 2 {
 				// TODO: fix this
@@ -710,7 +786,7 @@ func modelType(schema *base.SchemaProxy) (ModelType, error) {
 			var nullable bool
 			var sp *base.SchemaProxy
 			for _, v := range sch.AnyOf {
-				if v.Schema().Type[0] == "null" {
+				if isNullSchema(v.Schema()) {
 					nullable = true
 					continue
 				}
@@ -729,7 +805,11 @@ func modelType(schema *base.SchemaProxy) (ModelType, error) {
 		return newPrimitiveModelType("any"), nil
 	}
 
-	if sch.Type[0] == "object" {
+	if len(types) > 1 {
+		return newUnionModelType(schema, types)
+	}
+
+	if types[0] == "object" {
 		if sch.AdditionalProperties != nil {
 			// we have a map!
 			if sch.AdditionalProperties.N == 1 && sch.AdditionalProperties.B {
@@ -741,7 +821,7 @@ func modelType(schema *base.SchemaProxy) (ModelType, error) {
 		return newObjectModelType(strings.TrimPrefix(schema.GetReference(), "#/components/schemas/")), nil
 	}
 
-	if sch.Type[0] == "array" {
+	if types[0] == "array" {
 		mt, err := modelType(sch.Items.A)
 		if err != nil {
 			return nil, err
@@ -763,10 +843,27 @@ func modelType(schema *base.SchemaProxy) (ModelType, error) {
 			}
 		*/
 
+		if u, ok := mt.(*UnionModelType); ok {
+			return newSliceModelType(u), nil
+		}
+
 		return newSliceModelType(newPrimitiveModelType(dataType)), nil
 	}
 
-	switch sch.Type[0] {
+	if ref := schema.GetReference(); ref != "" && types[0] == "string" {
+		// it's an enum
+		return newObjectModelType(strings.TrimPrefix(schema.GetReference(), "#/components/schemas/")), nil
+	}
+
+	return scalarModelType(schema, types[0])
+}
+
+// scalarModelType returns the type of a schema of the given type, which isn't
+// an object or an array.
+func scalarModelType(schema *base.SchemaProxy, t string) (ModelType, error) {
+	sch := schema.Schema()
+
+	switch t {
 	case "boolean":
 		return newPrimitiveModelType("bool"), nil
 	case "integer":
@@ -792,10 +889,6 @@ func modelType(schema *base.SchemaProxy) (ModelType, error) {
 			return newPrimitiveModelType("float64"), nil
 		}
 	case "string":
-		if ref := schema.GetReference(); ref != "" {
-			// it's an enum
-			return newObjectModelType(strings.TrimPrefix(schema.GetReference(), "#/components/schemas/")), nil
-		}
 		if sch.Format == "date-time" {
 			return newImportedModelType("time.Time", Import{Package: "time"}), nil
 		}
@@ -809,7 +902,7 @@ func modelType(schema *base.SchemaProxy) (ModelType, error) {
 
 		return newPrimitiveModelType("string"), nil
 	default:
-		return newPrimitiveModelType(sch.Type[0]), nil
+		return newPrimitiveModelType(t), nil
 	}
 }
 
@@ -937,6 +1030,123 @@ func getErrorResponses(op *v3high.Operation) ([]errorResponse, error) {
 	return data, nil
 }
 
+// JSClass returns the name of the class of the errors the JS client throws for
+// the error response.
+func (e errorResponse) JSClass() string {
+	t := strings.TrimPrefix(e.Type, "models.")
+	if elem, ok := strings.CutPrefix(t, "[]"); ok {
+		t = elem + "List"
+	}
+	return typeName(t) + "Error"
+}
+
+// JSKey returns the error response code as a JS object key.
+func (e errorResponse) JSKey() string {
+	if _, err := strconv.Atoi(e.Code); err == nil || e.Code == "default" {
+		return e.Code
+	}
+	return strconv.Quote(e.Code)
+}
+
+// JSErrorResponses returns the distinct error responses of the handlers by
+// the class the JS client throws for them.
+func (t TemplateData) JSErrorResponses() []errorResponse {
+	var responses []errorResponse
+	seen := make(map[string]struct{})
+	for _, h := range t.Handlers {
+		for _, e := range h.ErrorResponseTypes {
+			if _, ok := seen[e.JSClass()]; ok {
+				continue
+			}
+			seen[e.JSClass()] = struct{}{}
+			responses = append(responses, e)
+		}
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].JSClass() < responses[j].JSClass() })
+	return responses
+}
+
+// declaredResponse is a response declared on an operation along with the
+// content types it may be sent with.
+type declaredResponse struct {
+	Code         string
+	ContentTypes []string
+}
+
+func getDeclaredResponses(resp *v3high.Responses) []declaredResponse {
+	data := make([]declaredResponse, 0)
+	if resp == nil {
+		return data
+	}
+
+	add := func(code string, r *v3high.Response) {
+		dr := declaredResponse{Code: code}
+		if r.Content != nil {
+			for cPair := r.Content.First(); cPair != nil; cPair = cPair.Next() {
+				dr.ContentTypes = append(dr.ContentTypes, cPair.Key())
+			}
+		}
+		sort.Strings(dr.ContentTypes)
+		data = append(data, dr)
+	}
+
+	for pair := resp.Codes.First(); pair != nil; pair = pair.Next() {
+		add(pair.Key(), pair.Value())
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i].Code < data[j].Code })
+
+	if resp.Default != nil {
+		add("default", resp.Default)
+	}
+
+	return data
+}
+
+// streamMediaTypes are the media types whose responses are produced and
+// consumed as a sequence of items instead of a single buffered body.
+var streamMediaTypes = []string{"text/event-stream", "application/x-ndjson"}
+
+// getStream returns the streaming media type of the successful response along
+// with the type of the items in the stream. The item schema is read from the
+// media type's itemSchema, falling back to its schema.
+func getStream(op *v3high.Operation) (string, string, error) {
+	if op.Responses == nil {
+		return "", "", nil
+	}
+
+	for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+		code := pair.Key()
+		r := pair.Value()
+		if !strings.HasPrefix(code, "2") || r.Content == nil {
+			continue
+		}
+
+		for _, contentType := range streamMediaTypes {
+			media, ok := r.Content.Get(contentType)
+			if !ok {
+				continue
+			}
+
+			schema := media.ItemSchema
+			if schema == nil {
+				schema = media.Schema
+			}
+			if schema == nil {
+				return "", "", fmt.Errorf("%s response has no item schema", contentType)
+			}
+
+			mt, err := modelType(schema)
+			if err != nil {
+				return "", "", err
+			}
+
+			return contentType, mt.Type(), nil
+		}
+	}
+
+	return "", "", nil
+}
+
 func getResponseType(op *v3high.Operation) (string, error) {
 	if op.Responses == nil {
 		return "", nil
@@ -945,6 +1155,12 @@ func getResponseType(op *v3high.Operation) (string, error) {
 		return "*FileDownloadResponse", nil
 	}
 
+	if contentType, itemType, err := getStream(op); err != nil {
+		return "", err
+	} else if contentType != "" {
+		return "iter.Seq2[" + itemType + ", error]", nil
+	}
+
 	for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
 		code := pair.Key()
 		r := pair.Value()
@@ -987,6 +1203,10 @@ func getRequestBodyType(op *v3high.Operation) (string, error) {
 		return "", nil
 	}
 
+	if t := requestModelType(mediaType.Schema); t != "" {
+		return t, nil
+	}
+
 	mt, err := modelType(mediaType.Schema)
 	if err != nil {
 		return "", err
@@ -995,7 +1215,8 @@ func getRequestBodyType(op *v3high.Operation) (string, error) {
 	return mt.Type(), err
 }
 
-func renderTemplate(tmpl string, data TemplateData, dest io.Writer, pkgModels, language string) error {
+// templateFuncs returns the functions available to the templates.
+func templateFuncs(pkgModels string) template.FuncMap {
 	funcs := sprig.FuncMap()
 	funcs["typename"] = typeName
 	funcs["argname"] = argName
@@ -1003,14 +1224,13 @@ func renderTemplate(tmpl string, data TemplateData, dest io.Writer, pkgModels, l
 	funcs["httpstatus"] = statusStringToName
 	funcs["models"] = models(pkgModels)
 	funcs["formatComment"] = formatComment
+	funcs["quotedstrings"] = func(strs []string) string { return quotedStrings(strs...) }
+	return funcs
+}
 
-	t1, err := template.New("").Funcs(funcs).Parse(tmpl)
-	if err != nil {
-		return err
-	}
-
+func renderTemplate(t *template.Template, data TemplateData, dest io.Writer, language string) error {
 	var buf bytes.Buffer
-	if err := t1.Execute(&buf, data); err != nil {
+	if err := t.Execute(&buf, data); err != nil {
 		return err
 	}
 
@@ -1065,15 +1285,20 @@ func renderTemplate(tmpl string, data TemplateData, dest io.Writer, pkgModels, l
 }
 
 func models(pkgModels string) func(string) string {
-	return func(in string) string {
+	var fn func(string) string
+	fn = func(in string) string {
 		if pkgModels == "" {
 			return in
 		}
 		if _, ok := primitiveTypes[in]; ok {
 			return in
 		}
+		if item, ok := strings.CutPrefix(in, "iter.Seq2["); ok {
+			return "iter.Seq2[" + fn(strings.TrimSuffix(item, ", error]")) + ", error]"
+		}
 		return "models." + in
 	}
+	return fn
 }
 
 type TemplateData struct {
@@ -1081,11 +1306,41 @@ type TemplateData struct {
 	GeneratorInfo    generatorInfo
 	PackageName      string
 	Handlers         []Handler
+	Webhooks         []Webhook
+	Callbacks        []Callback
 	Models           Models
-	Security         []Security
+	SecuritySchemes  []SecurityScheme
 	PkgModels        string
 	HasFileDownloads bool
 	Language         string
+
+	// Spec is the merged OpenAPI document, rendered as YAML.
+	Spec string
+}
+
+// SpecLiteral returns the merged spec as a Go raw string literal.
+func (t TemplateData) SpecLiteral() string {
+	return "`" + strings.ReplaceAll(t.Spec, "`", "` + \"`\" + `") + "`"
+}
+
+// HasIdempotent reports whether any handler is marked with x-idempotent.
+func (t TemplateData) HasIdempotent() bool {
+	for _, h := range t.Handlers {
+		if h.Idempotent {
+			return true
+		}
+	}
+	return false
+}
+
+// HasStreams reports whether any handler responds with a stream of items.
+func (t TemplateData) HasStreams() bool {
+	for _, h := range t.Handlers {
+		if h.IsStream() {
+			return true
+		}
+	}
+	return false
 }
 
 type Models []Model
@@ -1150,6 +1405,27 @@ type Field struct {
 	Required       bool
 	NoPointer      bool
 	DoNotSerialize bool
+	Deprecated     bool
+	Description    string
+	Format         string
+	Example        string
+	Constraints    []string
+
+	// Nullable fields are pointers, even when required, so null can be
+	// told apart from the zero value.
+	Nullable bool
+
+	// union is the union the field holds, if any.
+	union *UnionModelType
+
+	// ReadOnly and WriteOnly fields are left out of the request and response
+	// variants of the model respectively.
+	ReadOnly  bool
+	WriteOnly bool
+
+	// requestType is the type of the field in the request variant of the
+	// model, when it differs.
+	requestType string
 }
 
 type Handler struct {
@@ -1162,11 +1438,49 @@ type Handler struct {
 	ResponseType       string
 	Params             Params
 	RequestBodyType    string
-	Security           *Security
-	SecurityArgs       []string
 	ErrorResponseTypes []errorResponse
+	Responses          []declaredResponse
 	PkgModels          string
 	IsFileDownload     bool
+	StreamContentType  string
+	StreamItemType     string
+	Idempotent         bool
+	Retry              *RetryPolicy
+	Timeout            time.Duration
+	Pagination         *Pagination
+	pagination         *paginationExtension
+
+	// unions are the unions used by the request and response bodies.
+	unions []*UnionModelType
+
+	// SecurityRequirements are the alternative sets of security schemes the
+	// operation accepts.
+	SecurityRequirements [][]SecurityRequirement
+
+	// Group is the tag of the SVC interface the handler belongs to. It's
+	// empty unless handlers are grouped by tag.
+	Group string
+
+	// Deprecated is set for operations marked as deprecated or with a
+	// sunset date, the date the operation will be removed on.
+	Deprecated bool
+	Sunset     time.Time
+}
+
+// IsStream reports whether the handler responds with a stream of items.
+func (h Handler) IsStream() bool {
+	return h.StreamContentType != ""
+}
+
+// IsEventStream reports whether the handler responds with server-sent events.
+func (h Handler) IsEventStream() bool {
+	return h.StreamContentType == "text/event-stream"
+}
+
+// ReturnsReader reports whether the client hands the response body back to the
+// caller instead of decoding it.
+func (h Handler) ReturnsReader() bool {
+	return h.IsFileDownload || h.IsStream()
 }
 
 func (h Handler) Comment() string {
@@ -1334,24 +1648,14 @@ func (h Handler) ParameterizedURIJS() (string, error) {
 	return strings.Join(pieces, "/"), nil
 }
 
-func (t TemplateData) SecurityArgs() string {
-	var args []string
-	for _, v := range t.Security {
-		args = append(args, fmt.Sprintf("%s %s", argName(v.Name), typeName(v.Name)))
-	}
-
-	return strings.Join(args, ", ")
-}
-
 func (t TemplateData) Routes() []Route {
 	var routes []Route
 	for _, h := range t.Handlers {
 		routes = append(routes, Route{
-			Path:         h.Path,
-			Handler:      h.UnexportedName(),
-			Method:       h.Method,
-			Security:     h.Security,
-			SecurityArgs: h.SecurityArgs,
+			Path:                 h.Path,
+			Handler:              h.UnexportedName(),
+			Method:               h.Method,
+			SecurityRequirements: h.SecurityRequirements,
 		})
 	}
 
@@ -1401,6 +1705,10 @@ func (h Handler) TypeList(lang string) (string, error) {
 		if h.Params.HasQueryParams() {
 			data = append(data, "query_params={}")
 		}
+		if h.Params.HasHeaderParams() {
+			data = append(data, "header_params={}")
+		}
+		data = append(data, "{ signal } = {}")
 	default:
 		return "", fmt.Errorf("unsupported language %q", lang)
 	}
@@ -1457,47 +1765,50 @@ type Param struct {
 	Required         bool
 	RetrievalName    string
 	EnumeratedValues []string
+	Sensitive        bool
+	Deprecated       bool
 }
 
-//go:embed partials/param_int.txt
-var partialParseInt string
-
 func (p Param) Enumerated() bool {
 	return p.Type == "string" && len(p.EnumeratedValues) > 0
 }
 
 func (p Param) Field() Field {
 	return Field{
-		Name:     typeName(p.Name),
-		Type:     p.Type,
-		Required: p.Required,
+		Name:       typeName(p.Name),
+		Type:       p.Type,
+		Required:   p.Required,
+		Deprecated: p.Deprecated,
 	}
 }
 
-func (p Param) PathAssignment() (string, error) {
-	if p.Location != "path" {
-		return "", errors.New("called PathAssignment on non path param")
+// URLParamName returns the name the path parameter is retrieved from the
+// router with.
+func (p Param) URLParamName() string {
+	if p.RetrievalName != "" {
+		return p.RetrievalName
 	}
+	return p.Name
+}
 
-	name := p.Name
-	if p.RetrievalName != "" {
-		name = p.RetrievalName
+// BitSize returns the bit size of an integer path parameter.
+func (p Param) BitSize() (int, error) {
+	if p.Location != "path" {
+		return 0, errors.New("called BitSize on non path param")
 	}
 
 	switch p.Type {
 	// TODO: support bool, floats
-	case "string":
-		return fmt.Sprintf("%s := chi.URLParam(r, `%s`)", argName(p.Name), name), nil
 	case "int8":
-		return fmt.Sprintf(partialParseInt, argName(p.Name), name, 8), nil
+		return 8, nil
 	case "int16":
-		return fmt.Sprintf(partialParseInt, argName(p.Name), name, 16), nil
+		return 16, nil
 	case "int32":
-		return fmt.Sprintf(partialParseInt, argName(p.Name), name, 32), nil
+		return 32, nil
 	case "int", "int64":
-		return fmt.Sprintf(partialParseInt, argName(p.Name), name, 64), nil
+		return 64, nil
 	default:
-		return "", fmt.Errorf("PathAssignment called with unsupported type %s", p.Type)
+		return 0, fmt.Errorf("BitSize called with unsupported type %s", p.Type)
 	}
 }
 
@@ -1523,38 +1834,60 @@ func (p Param) FormattingFunc() (string, error) {
 	}
 }
 
-type Route struct {
-	Path         string
-	Method       string
-	Handler      string
-	Security     *Security
-	SecurityArgs []string
+// SlogAttr returns the log/slog attribute constructor for the param, using
+// value as the expression that yields the param's value. Sensitive params are
+// always redacted.
+func (p Param) SlogAttr(value string) string {
+	if p.Sensitive {
+		return fmt.Sprintf("slog.String(%q, %q)", p.Name, "[REDACTED]")
+	}
+
+	switch p.Type {
+	case "string":
+		return fmt.Sprintf("slog.String(%q, %s)", p.Name, value)
+	case "int8", "int16", "int32":
+		return fmt.Sprintf("slog.Int(%q, int(%s))", p.Name, value)
+	case "int":
+		return fmt.Sprintf("slog.Int(%q, %s)", p.Name, value)
+	case "int64":
+		return fmt.Sprintf("slog.Int64(%q, %s)", p.Name, value)
+	case "float32":
+		return fmt.Sprintf("slog.Float64(%q, float64(%s))", p.Name, value)
+	case "float64":
+		return fmt.Sprintf("slog.Float64(%q, %s)", p.Name, value)
+	case "bool":
+		return fmt.Sprintf("slog.Bool(%q, %s)", p.Name, value)
+	case "time.Time":
+		return fmt.Sprintf("slog.Time(%q, %s)", p.Name, value)
+	default:
+		return fmt.Sprintf("slog.Any(%q, %s)", p.Name, value)
+	}
 }
 
-func (r Route) GetRoute() (string, error) {
-	if r.Security != nil {
-		idx, err := r.Security.GetPermutationIndex(r.SecurityArgs)
-		if err != nil {
-			return "", err
-		}
+type Route struct {
+	Path                 string
+	Method               string
+	Handler              string
+	SecurityRequirements [][]SecurityRequirement
+}
 
+func (r Route) GetRoute() string {
+	if len(r.SecurityRequirements) > 0 {
 		return fmt.Sprintf(
-			"s.router.With(%sAuthzPerm%d.Then).%s(`%s`, s.%s)",
-			argName(r.Security.Name),
-			idx,
+			"s.router.With(%s).%s(`%s`, s.%s)",
+			authenticatedLiteral(r.SecurityRequirements),
 			methodFunc(r.Method),
 			r.Path,
 			r.Handler,
-		), nil
+		)
 	}
 
 	return fmt.Sprintf(
-			"s.router.%s(`%s`, s.%s)",
-			methodFunc(r.Method),
-			r.Path,
-			r.Handler,
-		),
-		nil
+		"s.router.%s(`%s`, s.%s)",
+		methodFunc(r.Method),
+		r.Path,
+		r.Handler,
+	)
 }
 
 func methodFunc(method string) string {
@@ -2,7 +2,10 @@ package template
 
 import (
 	"fmt"
+	"os"
+	"regexp"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -337,3 +340,88 @@ func TestFieldLess2(t *testing.T) {
 	}
 
 }
+
+func TestParamSlogAttr(t *testing.T) {
+	tests := []struct {
+		param    Param
+		value    string
+		expected string
+	}{
+		{Param{Name: "id", Type: "string"}, "id", `slog.String("id", id)`},
+		{Param{Name: "num", Type: "int32"}, "num", `slog.Int("num", int(num))`},
+		{Param{Name: "num", Type: "int64"}, "num", `slog.Int64("num", num)`},
+		{Param{Name: "ratio", Type: "float32"}, "*qp.Ratio", `slog.Float64("ratio", float64(*qp.Ratio))`},
+		{Param{Name: "verbose", Type: "bool"}, "*qp.Verbose", `slog.Bool("verbose", *qp.Verbose)`},
+		{Param{Name: "since", Type: "time.Time"}, "qp.Since", `slog.Time("since", qp.Since)`},
+		{Param{Name: "token", Type: "string", Sensitive: true}, "qp.Token", `slog.String("token", "[REDACTED]")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.param.SlogAttr(tt.value))
+		})
+	}
+}
+
+func TestTemplateDataSpecLiteral(t *testing.T) {
+	td := TemplateData{Spec: "description: use `foo`\n"}
+	require.Equal(t, "`description: use ` + \"`\" + `foo` + \"`\" + `\n`", td.SpecLiteral())
+}
+
+func TestModels(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"string", "string"},
+		{"Widget", "models.Widget"},
+		{"iter.Seq2[Widget, error]", "iter.Seq2[models.Widget, error]"},
+		{"iter.Seq2[string, error]", "iter.Seq2[string, error]"},
+	}
+
+	fn := models("github.com/example/somemodels")
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			require.Equal(t, tt.expected, fn(tt.input))
+		})
+	}
+}
+
+func TestErrorResponseJS(t *testing.T) {
+	tests := []struct {
+		input errorResponse
+		class string
+		key   string
+	}{
+		{errorResponse{Code: "404", Type: "Problem"}, "ProblemError", "404"},
+		{errorResponse{Code: "4XX", Type: "models.Problem"}, "ProblemError", `"4XX"`},
+		{errorResponse{Code: "default", Type: "[]Problem"}, "ProblemListError", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input.Code, func(t *testing.T) {
+			require.Equal(t, tt.class, tt.input.JSClass())
+			require.Equal(t, tt.key, tt.input.JSKey())
+		})
+	}
+}
+
+// TestRequestValidatorUnmatchedRoutes checks in the generated request validator
+// that the requests matching none of the operations of the spec, like health
+// checks, are passed on before being validated.
+func TestRequestValidatorUnmatchedRoutes(t *testing.T) {
+	b, err := os.ReadFile("testdata/cases/pagination/expected/request_validation.go.txt")
+	require.NoError(t, err)
+	src := string(b)
+
+	var operations []string
+	for _, m := range regexp.MustCompile("operations\\.Method\\(\"(\\w+)\", `([^`]+)`").FindAllStringSubmatch(src, -1) {
+		operations = append(operations, m[1]+" "+m[2])
+	}
+	require.ElementsMatch(t, []string{"GET /v1/widgets", "GET /v1/gadgets", "GET /v1/groups/{id}/gizmos"}, operations)
+
+	unmatched := "if !operations.Match(chi.NewRouteContext(), r.Method, r.URL.Path) {\n\t\t\t\tnext.ServeHTTP(w, r)\n\t\t\t\treturn\n\t\t\t}"
+	i := strings.Index(src, unmatched)
+	require.NotEqual(t, -1, i)
+	require.Less(t, i, strings.Index(src, "v.ValidateHttpRequest(r)"))
+}
@@ -0,0 +1,165 @@
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+const (
+	extensionRetry   = "x-retry"
+	extensionTimeout = "x-timeout"
+)
+
+// defaultRetryAttempts is the number of attempts made for idempotent operations
+// without an x-retry extension.
+const defaultRetryAttempts = 3
+
+// defaultRetryStatuses are the statuses retried when the x-retry extension does
+// not list any.
+var defaultRetryStatuses = []int{429, 502, 503, 504}
+
+// idempotentMethods are retried by default.
+var idempotentMethods = map[string]struct{}{
+	"get":     {},
+	"head":    {},
+	"options": {},
+	"trace":   {},
+	"put":     {},
+	"delete":  {},
+}
+
+// retryExtension is the value of the x-retry extension on an operation.
+type retryExtension struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts *int `yaml:"maxAttempts"`
+
+	// Statuses are the response statuses that are retried.
+	Statuses []int `yaml:"statuses"`
+
+	// RetryAfter controls whether the Retry-After header of a response is
+	// honored. Defaults to true.
+	RetryAfter *bool `yaml:"retryAfter"`
+}
+
+// RetryPolicy describes how the client retries an operation.
+type RetryPolicy struct {
+	MaxAttempts int
+	Statuses    []int
+	RetryAfter  bool
+}
+
+// StatusList returns the retried statuses as a comma separated list of
+// net/http constants.
+func (p RetryPolicy) StatusList() string {
+	statuses := make([]string, 0, len(p.Statuses))
+	for _, v := range p.Statuses {
+		statuses = append(statuses, statusStringToName(strconv.Itoa(v)))
+	}
+	return strings.Join(statuses, ", ")
+}
+
+// getRetry builds the retry policy of an operation from its x-retry extension.
+// Operations without the extension are retried if their method is idempotent or
+// if they are marked with x-idempotent.
+func getRetry(op *v3high.Operation, method string, idempotent bool) (*RetryPolicy, error) {
+	p := RetryPolicy{
+		MaxAttempts: 1,
+		Statuses:    defaultRetryStatuses,
+		RetryAfter:  true,
+	}
+	if _, ok := idempotentMethods[strings.ToLower(method)]; ok || idempotent {
+		p.MaxAttempts = defaultRetryAttempts
+	}
+
+	node, ok := op.Extensions.Get(extensionRetry)
+	if !ok {
+		if p.MaxAttempts == 1 {
+			return nil, nil
+		}
+		return &p, nil
+	}
+
+	var ext retryExtension
+	if err := node.Decode(&ext); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", extensionRetry, err)
+	}
+
+	if ext.MaxAttempts != nil {
+		if *ext.MaxAttempts < 1 {
+			return nil, fmt.Errorf("%s maxAttempts must be at least 1, got %d", extensionRetry, *ext.MaxAttempts)
+		}
+		p.MaxAttempts = *ext.MaxAttempts
+	} else if p.MaxAttempts == 1 {
+		p.MaxAttempts = defaultRetryAttempts
+	}
+	if len(ext.Statuses) > 0 {
+		p.Statuses = ext.Statuses
+	}
+	if ext.RetryAfter != nil {
+		p.RetryAfter = *ext.RetryAfter
+	}
+
+	if p.MaxAttempts == 1 {
+		return nil, nil
+	}
+
+	return &p, nil
+}
+
+// getTimeout parses the x-timeout extension of an operation.
+func getTimeout(op *v3high.Operation) (time.Duration, error) {
+	str, err := getExtensionString(op.Extensions, extensionTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("%s was set, but not to a string value: %w", extensionTimeout, err)
+	}
+	if str == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", extensionTimeout, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive, got %s", extensionTimeout, str)
+	}
+
+	return d, nil
+}
+
+// TimeoutLiteral returns the timeout of the handler as a Go expression.
+func (h Handler) TimeoutLiteral() string {
+	return durationLiteral(h.Timeout)
+}
+
+func durationLiteral(d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	} {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.d, unit.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
+
+// HasReaderTimeouts reports whether any handler returning the response body to
+// the caller has a timeout.
+func (t TemplateData) HasReaderTimeouts() bool {
+	for _, h := range t.Handlers {
+		if h.ReturnsReader() && h.Timeout > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package template
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestGetRetry(t *testing.T) {
+	tests := []struct {
+		desc       string
+		method     string
+		idempotent bool
+		ext        string
+		expected   *RetryPolicy
+		err        string
+	}{
+		{"idempotent default", "get", false, "", &RetryPolicy{MaxAttempts: 3, Statuses: defaultRetryStatuses, RetryAfter: true}, ""},
+		{"non-idempotent default", "post", false, "", nil, ""},
+		{"x-idempotent", "post", true, "", &RetryPolicy{MaxAttempts: 3, Statuses: defaultRetryStatuses, RetryAfter: true}, ""},
+		{"non-idempotent opt in", "post", false, "statuses: [409]", &RetryPolicy{MaxAttempts: 3, Statuses: []int{409}, RetryAfter: true}, ""},
+		{"opt out", "get", false, "maxAttempts: 1", nil, ""},
+		{"retry after disabled", "put", false, "{maxAttempts: 2, retryAfter: false}", &RetryPolicy{MaxAttempts: 2, Statuses: defaultRetryStatuses}, ""},
+		{"invalid attempts", "get", false, "maxAttempts: 0", nil, "maxAttempts must be at least 1"},
+		{"invalid value", "get", false, "foo", nil, "decoding x-retry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			op := &v3high.Operation{Extensions: orderedmap.New[string, *yaml.Node]()}
+			if tt.ext != "" {
+				var node yaml.Node
+				require.NoError(t, yaml.Unmarshal([]byte(tt.ext), &node))
+				op.Extensions.Set(extensionRetry, node.Content[0])
+			}
+
+			result, err := getRetry(op, tt.method, tt.idempotent)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestDurationLiteral(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{2 * time.Hour, "2 * time.Hour"},
+		{90 * time.Minute, "90 * time.Minute"},
+		{30 * time.Second, "30 * time.Second"},
+		{1500 * time.Millisecond, "1500 * time.Millisecond"},
+		{1500 * time.Microsecond, "time.Duration(1500000)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input.String(), func(t *testing.T) {
+			require.Equal(t, tt.expected, durationLiteral(tt.input))
+		})
+	}
+}
+
+// TestClientNonIdempotentNotRetried checks in the generated client that an
+// operation without a retry policy, like a POST without x-retry, is attempted
+// once: it has no retry policy for the retryDoer and httpc retries no status.
+func TestClientNonIdempotentNotRetried(t *testing.T) {
+	src, err := os.ReadFile("testdata/cases/retry/expected/client.go.txt")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", src, 0)
+	require.NoError(t, err)
+
+	var body, statuses string
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name == "WidgetsCreate" && d.Recv != nil {
+				body = string(src[fset.Position(d.Body.Pos()).Offset:fset.Position(d.Body.End()).Offset])
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if vs, ok := spec.(*ast.ValueSpec); ok && vs.Names[0].Name == "noRetryStatuses" {
+					statuses = string(src[fset.Position(vs.Values[0].Pos()).Offset:fset.Position(vs.Values[0].End()).Offset])
+				}
+			}
+		}
+	}
+
+	require.NotEmpty(t, body)
+	require.NotContains(t, body, "withRetryPolicy")
+	require.Equal(t, 1, strings.Count(body, "RetryStatus(noRetryStatuses)"))
+	require.Equal(t, "httpc.StatusIn()", statuses)
+}
@@ -0,0 +1,315 @@
+package template
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// SecurityScheme is a security scheme defined in the components of the
+// document.
+type SecurityScheme struct {
+	Name string
+
+	// Type is one of apiKey, http, oauth2, openIdConnect or mutualTLS.
+	Type string
+
+	// Scheme is the HTTP authorization scheme (http type).
+	Scheme string
+
+	// In and ParamName locate the API key (apiKey type).
+	In        string
+	ParamName string
+
+	// TokenURL is the token URL of the client credentials flow (oauth2 type).
+	TokenURL string
+
+	// Scopes are the scopes declared by the flows of the scheme (oauth2 type)
+	// and the scopes operations require of it.
+	Scopes []SecurityScope
+}
+
+// SecurityScope is a scope of a security scheme.
+type SecurityScope struct {
+	Name        string
+	Description string
+}
+
+// SecurityRequirement is a security scheme required by an operation along with
+// the scopes the operation requires of it.
+type SecurityRequirement struct {
+	Scheme string
+	Scopes []string
+}
+
+func (s SecurityScheme) ExportedName() string {
+	return typeName(s.Name)
+}
+
+// IsAPIKey reports whether the scheme is an API key sent in a header, query
+// parameter or cookie.
+func (s SecurityScheme) IsAPIKey() bool {
+	return s.Type == "apiKey"
+}
+
+// IsBasic reports whether the scheme is HTTP basic authentication.
+func (s SecurityScheme) IsBasic() bool {
+	return s.Type == "http" && strings.EqualFold(s.Scheme, "basic")
+}
+
+// IsToken reports whether the scheme sends a bearer token in the Authorization
+// header.
+func (s SecurityScheme) IsToken() bool {
+	switch s.Type {
+	case "http":
+		return strings.EqualFold(s.Scheme, "bearer")
+	case "oauth2", "openIdConnect":
+		return true
+	}
+	return false
+}
+
+// CredentialType returns the type of the credential the server extracts from
+// requests for the scheme.
+func (s SecurityScheme) CredentialType() string {
+	switch {
+	case s.IsAPIKey():
+		return "APIKey"
+	case s.IsBasic():
+		return "BasicCredentials"
+	default:
+		return "BearerToken"
+	}
+}
+
+// ScopeType returns the name of the type of the scope constants of the scheme.
+func (s SecurityScheme) ScopeType() string {
+	return s.ExportedName() + "Scope"
+}
+
+// ScopeConst returns the name of the constant of a scope of the scheme.
+func (s SecurityScheme) ScopeConst(scope string) string {
+	return scopeConstName(s.Name, scope)
+}
+
+func scopeConstName(scheme, scope string) string {
+	return typeName(scheme) + "Scope" + typeName(scope)
+}
+
+// addScope adds a scope to the scheme unless it is already known.
+func (s *SecurityScheme) addScope(name, description string) {
+	for _, v := range s.Scopes {
+		if v.Name == name {
+			return
+		}
+	}
+	s.Scopes = append(s.Scopes, SecurityScope{Name: name, Description: description})
+}
+
+// SupportedByClient reports whether the client can attach credentials for the
+// scheme.
+func (s SecurityScheme) SupportedByClient() bool {
+	return s.IsAPIKey() || s.IsBasic() || s.IsToken()
+}
+
+func getSecuritySchemes(doc *v3high.Document) ([]SecurityScheme, error) {
+	if doc.Components == nil || doc.Components.SecuritySchemes == nil {
+		return nil, nil
+	}
+
+	var schemes []SecurityScheme
+	for pair := doc.Components.SecuritySchemes.First(); pair != nil; pair = pair.Next() {
+		name := pair.Key()
+		v := pair.Value()
+
+		s := SecurityScheme{
+			Name:      name,
+			Type:      v.Type,
+			Scheme:    v.Scheme,
+			In:        v.In,
+			ParamName: v.Name,
+		}
+
+		if s.IsAPIKey() {
+			switch s.In {
+			case "header", "query", "cookie":
+			default:
+				return nil, fmt.Errorf("security scheme %s: unsupported apiKey location %q", name, s.In)
+			}
+			if s.ParamName == "" {
+				return nil, fmt.Errorf("security scheme %s: apiKey name not set", name)
+			}
+		}
+
+		if v.Flows != nil {
+			if v.Flows.ClientCredentials != nil {
+				s.TokenURL = v.Flows.ClientCredentials.TokenUrl
+			}
+
+			for _, flow := range []*v3high.OAuthFlow{
+				v.Flows.Implicit,
+				v.Flows.Password,
+				v.Flows.ClientCredentials,
+				v.Flows.AuthorizationCode,
+			} {
+				if flow == nil || flow.Scopes == nil {
+					continue
+				}
+				for scope := flow.Scopes.First(); scope != nil; scope = scope.Next() {
+					s.addScope(scope.Key(), scope.Value())
+				}
+			}
+		}
+
+		schemes = append(schemes, s)
+	}
+
+	return schemes, nil
+}
+
+// getSecurityRequirements returns the alternative sets of security schemes an
+// operation accepts. Operations without security requirements inherit the
+// requirements of the document.
+func getSecurityRequirements(op *v3high.Operation, doc *v3high.Document) [][]SecurityRequirement {
+	reqs := op.Security
+	if reqs == nil {
+		reqs = doc.Security
+	}
+
+	var result [][]SecurityRequirement
+	for _, req := range reqs {
+		alternative := []SecurityRequirement{}
+		if req != nil && req.Requirements != nil {
+			for pair := req.Requirements.First(); pair != nil; pair = pair.Next() {
+				alternative = append(alternative, SecurityRequirement{
+					Scheme: pair.Key(),
+					Scopes: pair.Value(),
+				})
+			}
+		}
+		result = append(result, alternative)
+	}
+	return result
+}
+
+// resolveSecurityScopes checks that the security schemes required by the
+// handlers are defined and adds the scopes the handlers require to them.
+func resolveSecurityScopes(schemes []SecurityScheme, handlers []Handler) error {
+	for _, h := range handlers {
+		for _, alternative := range h.SecurityRequirements {
+			for _, req := range alternative {
+				i := slices.IndexFunc(schemes, func(s SecurityScheme) bool { return s.Name == req.Scheme })
+				if i == -1 {
+					return fmt.Errorf("%s: security scheme %q is not defined", h.Name, req.Scheme)
+				}
+				if !schemes[i].IsAPIKey() && !schemes[i].IsBasic() && !schemes[i].IsToken() {
+					return fmt.Errorf("%s: security scheme %q: type %s is not supported", h.Name, req.Scheme, schemes[i].Type)
+				}
+				for _, scope := range req.Scopes {
+					schemes[i].addScope(scope, "")
+				}
+			}
+		}
+	}
+
+	for i := range schemes {
+		slices.SortFunc(schemes[i].Scopes, func(a, b SecurityScope) int { return strings.Compare(a.Name, b.Name) })
+	}
+
+	return nil
+}
+
+// SecurityRequirementsLiteral returns the names of the security schemes
+// required by the handler as a Go [][]string literal.
+func (h Handler) SecurityRequirementsLiteral() string {
+	alternatives := make([]string, 0, len(h.SecurityRequirements))
+	for _, alternative := range h.SecurityRequirements {
+		names := make([]string, 0, len(alternative))
+		for _, req := range alternative {
+			names = append(names, req.Scheme)
+		}
+		alternatives = append(alternatives, "{"+quotedStrings(names...)+"}")
+	}
+	return "[][]string{" + strings.Join(alternatives, ", ") + "}"
+}
+
+// authenticatedLiteral returns the Go expression of the middleware
+// authenticating requests to the operation.
+func authenticatedLiteral(reqs [][]SecurityRequirement) string {
+	alternatives := make([]string, 0, len(reqs))
+	for _, alternative := range reqs {
+		funcs := make([]string, 0, len(alternative))
+		for _, req := range alternative {
+			scopes := make([]string, 0, len(req.Scopes))
+			for _, scope := range req.Scopes {
+				scopes = append(scopes, scopeConstName(req.Scheme, scope))
+			}
+			funcs = append(funcs, fmt.Sprintf("s.authenticate%s(%s)", typeName(req.Scheme), strings.Join(scopes, ", ")))
+		}
+		alternatives = append(alternatives, "[]authFunc{"+strings.Join(funcs, ", ")+"}")
+	}
+	return "s.authenticated(" + strings.Join(alternatives, ", ") + ")"
+}
+
+// ServerSecuritySchemes returns the security schemes required by at least one
+// operation.
+func (t TemplateData) ServerSecuritySchemes() []SecurityScheme {
+	var schemes []SecurityScheme
+	for _, s := range t.SecuritySchemes {
+		if t.requiresScheme(s.Name) {
+			schemes = append(schemes, s)
+		}
+	}
+	return schemes
+}
+
+func (t TemplateData) requiresScheme(name string) bool {
+	for _, h := range t.Handlers {
+		for _, alternative := range h.SecurityRequirements {
+			for _, req := range alternative {
+				if req.Scheme == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ServerCredentialTypes returns the credential types of the security schemes
+// required by the operations.
+func (t TemplateData) ServerCredentialTypes() []string {
+	var types []string
+	for _, s := range t.ServerSecuritySchemes() {
+		if !slices.Contains(types, s.CredentialType()) {
+			types = append(types, s.CredentialType())
+		}
+	}
+	slices.Sort(types)
+	return types
+}
+
+// ClientSecuritySchemes returns the security schemes the client can attach
+// credentials for.
+func (t TemplateData) ClientSecuritySchemes() []SecurityScheme {
+	var schemes []SecurityScheme
+	for _, v := range t.SecuritySchemes {
+		if v.SupportedByClient() {
+			schemes = append(schemes, v)
+		}
+	}
+	return schemes
+}
+
+// HasClientCredentials reports whether any security scheme uses the OAuth2
+// client credentials flow.
+func (t TemplateData) HasClientCredentials() bool {
+	for _, v := range t.SecuritySchemes {
+		if v.IsToken() && v.TokenURL != "" {
+			return true
+		}
+	}
+	return false
+}
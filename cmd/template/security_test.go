@@ -0,0 +1,97 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecurityRequirements(t *testing.T) {
+	requirement := func(names ...string) *base.SecurityRequirement {
+		m := orderedmap.New[string, []string]()
+		for _, v := range names {
+			m.Set(v, []string{"read"})
+		}
+		return &base.SecurityRequirement{Requirements: m}
+	}
+	req := func(name string) SecurityRequirement {
+		return SecurityRequirement{Scheme: name, Scopes: []string{"read"}}
+	}
+
+	global := []*base.SecurityRequirement{requirement("Global")}
+
+	tests := []struct {
+		desc     string
+		op       []*base.SecurityRequirement
+		doc      []*base.SecurityRequirement
+		expected [][]SecurityRequirement
+		literal  string
+	}{
+		{"none", nil, nil, nil, "[][]string{}"},
+		{"inherited", nil, global, [][]SecurityRequirement{{req("Global")}}, `[][]string{{"Global"}}`},
+		{"explicitly unsecured", []*base.SecurityRequirement{}, global, nil, "[][]string{}"},
+		{"overridden", []*base.SecurityRequirement{requirement("A", "B"), requirement("C")}, global, [][]SecurityRequirement{{req("A"), req("B")}, {req("C")}}, `[][]string{{"A", "B"}, {"C"}}`},
+		{"optional", []*base.SecurityRequirement{requirement("A"), requirement()}, nil, [][]SecurityRequirement{{req("A")}, {}}, `[][]string{{"A"}, {}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			result := getSecurityRequirements(&v3high.Operation{Security: tt.op}, &v3high.Document{Security: tt.doc})
+			require.Equal(t, tt.expected, result)
+			require.Equal(t, tt.literal, Handler{SecurityRequirements: result}.SecurityRequirementsLiteral())
+		})
+	}
+}
+
+func TestResolveSecurityScopes(t *testing.T) {
+	handler := func(reqs ...SecurityRequirement) Handler {
+		return Handler{Name: "op", SecurityRequirements: [][]SecurityRequirement{reqs}}
+	}
+
+	tests := []struct {
+		desc     string
+		handlers []Handler
+		expected []SecurityScope
+		err      string
+	}{
+		{
+			"merged and sorted",
+			[]Handler{
+				handler(SecurityRequirement{Scheme: "OAuth", Scopes: []string{"write", "admin"}}),
+				handler(SecurityRequirement{Scheme: "OAuth", Scopes: []string{"read"}}),
+			},
+			[]SecurityScope{{"admin", ""}, {"read", "Read things"}, {"write", ""}},
+			"",
+		},
+		{"undefined", []Handler{handler(SecurityRequirement{Scheme: "Other"})}, nil, `security scheme "Other" is not defined`},
+		{"unsupported", []Handler{handler(SecurityRequirement{Scheme: "TLS"})}, nil, "type mutualTLS is not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			schemes := []SecurityScheme{
+				{Name: "OAuth", Type: "oauth2", Scopes: []SecurityScope{{"read", "Read things"}}},
+				{Name: "TLS", Type: "mutualTLS"},
+			}
+
+			err := resolveSecurityScopes(schemes, tt.handlers)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, schemes[0].Scopes)
+		})
+	}
+}
+
+func TestAuthenticatedLiteral(t *testing.T) {
+	result := authenticatedLiteral([][]SecurityRequirement{
+		{{Scheme: "OAuth", Scopes: []string{"widgets:write"}}, {Scheme: "my_key"}},
+		{},
+	})
+	require.Equal(t, "s.authenticated([]authFunc{s.authenticateOAuth(OAuthScopeWidgetsWrite), s.authenticateMyKey()}, []authFunc{})", result)
+}
@@ -0,0 +1,94 @@
+package template
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ServiceGroup is a set of handlers served by the same SVC interface. Without
+// --group-by-tag every handler is in a single group, with an empty Tag.
+// Otherwise the handlers are grouped by their first tag, untagged handlers
+// staying in the group with an empty Tag.
+type ServiceGroup struct {
+	Tag      string
+	Handlers []Handler
+}
+
+// Prefix returns the prefix of the names of the types generated for the group,
+// e.g. Widgets for the widgets tag.
+func (g ServiceGroup) Prefix() string {
+	return servicePrefix(g.Tag)
+}
+
+// SVC returns the name of the interface of the group.
+func (g ServiceGroup) SVC() string {
+	return g.Prefix() + "SVC"
+}
+
+// Field returns the name of the HTTPServer field and NewHTTPServer argument
+// holding the implementation of the group.
+func (g ServiceGroup) Field() string {
+	return serviceField(g.Tag)
+}
+
+// Type returns the name of a type generated for the group, e.g.
+// WidgetsLoggingService for the LoggingService of the widgets tag.
+func (g ServiceGroup) Type(name string) string {
+	return g.Prefix() + name
+}
+
+func servicePrefix(tag string) string {
+	if tag == "" {
+		return ""
+	}
+	return typeName(tag)
+}
+
+func serviceField(tag string) string {
+	if tag == "" {
+		return "svc"
+	}
+	return argName(typeName(tag)) + "SVC"
+}
+
+// SVCField returns the name of the HTTPServer field holding the implementation
+// of the SVC interface the handler belongs to.
+func (h Handler) SVCField() string {
+	return serviceField(h.Group)
+}
+
+// Services returns the handlers grouped by SVC interface, sorted by tag.
+func (t TemplateData) Services() []ServiceGroup {
+	var groups []ServiceGroup
+	for _, h := range t.Handlers {
+		i := slices.IndexFunc(groups, func(g ServiceGroup) bool { return g.Tag == h.Group })
+		if i == -1 {
+			groups = append(groups, ServiceGroup{Tag: h.Group})
+			i = len(groups) - 1
+		}
+		groups[i].Handlers = append(groups[i].Handlers, h)
+	}
+
+	if len(groups) == 0 {
+		// There's always a SVC, even without operations.
+		groups = append(groups, ServiceGroup{})
+	}
+
+	slices.SortFunc(groups, func(a, b ServiceGroup) int { return strings.Compare(a.Tag, b.Tag) })
+	return groups
+}
+
+// checkServiceGroups checks that no two tags the handlers are grouped by
+// generate the same names.
+func checkServiceGroups(handlers []Handler) error {
+	tags := make(map[string]string)
+	for _, h := range handlers {
+		prefix := servicePrefix(h.Group)
+		if other, ok := tags[prefix]; ok && other != h.Group {
+			return fmt.Errorf("tags %q and %q both generate %sSVC", other, h.Group, prefix)
+		}
+		tags[prefix] = h.Group
+	}
+	return nil
+}
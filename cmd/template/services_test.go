@@ -0,0 +1,62 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServices(t *testing.T) {
+	data := TemplateData{
+		Handlers: []Handler{
+			{Name: "widgetsList", Group: "widgets"},
+			{Name: "health"},
+			{Name: "gadgetsDelete", Group: "gadget admin"},
+			{Name: "widgetsGet", Group: "widgets"},
+		},
+	}
+
+	groups := data.Services()
+	require.Len(t, groups, 3)
+
+	tests := []struct {
+		tag      string
+		handlers []string
+		svc      string
+		field    string
+		logging  string
+	}{
+		{"", []string{"health"}, "SVC", "svc", "LoggingService"},
+		{"gadget admin", []string{"gadgetsDelete"}, "GadgetAdminSVC", "gadgetAdminSVC", "GadgetAdminLoggingService"},
+		{"widgets", []string{"widgetsList", "widgetsGet"}, "WidgetsSVC", "widgetsSVC", "WidgetsLoggingService"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			g := groups[i]
+			require.Equal(t, tt.tag, g.Tag)
+
+			var names []string
+			for _, h := range g.Handlers {
+				names = append(names, h.Name)
+				require.Equal(t, tt.field, h.SVCField())
+			}
+			require.Equal(t, tt.handlers, names)
+			require.Equal(t, tt.svc, g.SVC())
+			require.Equal(t, tt.field, g.Field())
+			require.Equal(t, tt.logging, g.Type("LoggingService"))
+		})
+	}
+
+	// There's always a SVC.
+	require.Equal(t, []ServiceGroup{{}}, TemplateData{}.Services())
+}
+
+func TestCheckServiceGroups(t *testing.T) {
+	require.NoError(t, checkServiceGroups([]Handler{{Group: "widgets"}, {Group: "widgets"}, {}}))
+	require.EqualError(
+		t,
+		checkServiceGroups([]Handler{{Group: "widgets"}, {Group: "Widgets"}}),
+		`tags "widgets" and "Widgets" both generate WidgetsSVC`,
+	)
+}
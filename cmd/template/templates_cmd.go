@@ -0,0 +1,378 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/spf13/cobra"
+)
+
+// templateFuncDocs documents the functions templateFuncs adds to the sprig
+// functions.
+var templateFuncDocs = []struct {
+	Name        string
+	Usage       string
+	Description string
+}{
+	{"argname", "argname <string>", "Lower camel cased name, for unexported identifiers and arguments."},
+	{"formatComment", "formatComment <string>", "Wraps the string into // comment lines."},
+	{"httpstatus", "httpstatus <code>", "The net/http constant of a status code, e.g. http.StatusOK."},
+	{"models", "models <type>", "Qualifies a model type with the models package when --pkg-models is set."},
+	{"quotedstrings", "quotedstrings <[]string>", "The strings as a comma separated list of Go string literals."},
+	{"snake", "snake <string>", "Snake cased name."},
+	{"typename", "typename <string>", "Camel cased name, for exported identifiers and types."},
+}
+
+// embeddedTemplate is a template or partial of the embedded templates.
+type embeddedTemplate struct {
+	// Name is the name of the template, relative to the templates directory
+	// and without the language and extension.
+	Name        string
+	Language    string
+	Description string
+}
+
+func (t embeddedTemplate) file() string {
+	return t.Name + "." + t.Language + ".tmpl"
+}
+
+func (t embeddedTemplate) isPartial() bool {
+	return strings.HasPrefix(t.Name, "partials/")
+}
+
+var templateDescription = regexp.MustCompile(`^\{\{-?\s*/\*(?s:(.*?))\*/`)
+
+// embeddedTemplates lists the embedded templates and partials. The
+// description of a template is read from the comment it starts with.
+func embeddedTemplates() ([]embeddedTemplate, error) {
+	embedded, err := fs.Sub(templates, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []embeddedTemplate
+	err = fs.WalkDir(embedded, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".tmpl") {
+			return err
+		}
+
+		name, lang, ok := strings.Cut(strings.TrimSuffix(p, ".tmpl"), ".")
+		if !ok {
+			return fmt.Errorf("template %s: language not found in file name", p)
+		}
+
+		data, err := fs.ReadFile(embedded, p)
+		if err != nil {
+			return err
+		}
+
+		t := embeddedTemplate{Name: name, Language: lang}
+		if m := templateDescription.FindSubmatch(data); m != nil {
+			t.Description = strings.Join(strings.Fields(string(m[1])), " ")
+		}
+		result = append(result, t)
+		return nil
+	})
+
+	return result, err
+}
+
+// findEmbeddedTemplate finds the embedded template or partial of a language.
+func findEmbeddedTemplate(name, language string) (embeddedTemplate, []byte, error) {
+	list, err := embeddedTemplates()
+	if err != nil {
+		return embeddedTemplate{}, nil, err
+	}
+
+	for _, t := range list {
+		if t.Language != language || (t.Name != name && t.Name != path.Join("partials", name)) {
+			continue
+		}
+		data, err := templates.ReadFile(path.Join("templates", t.file()))
+		return t, data, err
+	}
+
+	return embeddedTemplate{}, nil, fmt.Errorf("template %q not found for language %s", name, language)
+}
+
+// referencedPartials returns the partials invoked by a template, including the
+// partials invoked by those partials.
+func referencedPartials(tmpl []byte, language string) ([]embeddedTemplate, error) {
+	list, err := embeddedTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	// The names of the blocks defined by each partial.
+	definedBy := make(map[string]embeddedTemplate)
+	sources := make(map[string][]byte)
+	for _, t := range list {
+		if !t.isPartial() || t.Language != language {
+			continue
+		}
+		data, err := templates.ReadFile(path.Join("templates", t.file()))
+		if err != nil {
+			return nil, err
+		}
+		sources[t.Name] = data
+
+		names, _, err := templateNames(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", t.file(), err)
+		}
+		for _, n := range names {
+			definedBy[n] = t
+		}
+	}
+
+	var (
+		result []embeddedTemplate
+		queue  = [][]byte{tmpl}
+	)
+	for len(queue) > 0 {
+		defined, invoked, err := templateNames(queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+
+		for _, name := range invoked {
+			p, ok := definedBy[name]
+			if !ok || slices.Contains(defined, name) || slices.Contains(result, p) {
+				continue
+			}
+			result = append(result, p)
+			queue = append(queue, sources[p.Name])
+		}
+	}
+
+	slices.SortFunc(result, func(a, b embeddedTemplate) int { return strings.Compare(a.Name, b.Name) })
+	return result, nil
+}
+
+// templateNames parses a template and returns the names of the blocks it
+// defines and of the templates it invokes.
+func templateNames(text []byte) (defined, invoked []string, err error) {
+	t, err := template.New("").Funcs(templateFuncs("")).Parse(string(text))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, v := range t.Templates() {
+		if v.Name() != "" {
+			defined = append(defined, v.Name())
+		}
+		if v.Tree != nil {
+			invoked = appendInvoked(invoked, v.Tree.Root)
+		}
+	}
+
+	return defined, invoked, nil
+}
+
+func appendInvoked(names []string, node parse.Node) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return names
+		}
+		for _, v := range n.Nodes {
+			names = appendInvoked(names, v)
+		}
+	case *parse.IfNode:
+		names = appendInvoked(names, n.List)
+		names = appendInvoked(names, n.ElseList)
+	case *parse.RangeNode:
+		names = appendInvoked(names, n.List)
+		names = appendInvoked(names, n.ElseList)
+	case *parse.WithNode:
+		names = appendInvoked(names, n.List)
+		names = appendInvoked(names, n.ElseList)
+	case *parse.TemplateNode:
+		if !slices.Contains(names, n.Name) {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+// NewTemplatesCmd sets up the command inspecting the embedded templates.
+func NewTemplatesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "templates",
+		Short:        "Lists, shows and ejects the embedded templates",
+		SilenceUsage: true,
+	}
+
+	cmd.AddCommand(
+		newTemplatesListCmd(),
+		newTemplatesShowCmd(),
+		newTemplatesEjectCmd(),
+	)
+
+	return cmd
+}
+
+func newTemplatesListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "Lists the embedded templates and the template functions",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listTemplates(cmd.OutOrStdout())
+		},
+	}
+}
+
+func listTemplates(out io.Writer) error {
+	list, err := embeddedTemplates()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TEMPLATE\tLANGUAGE\tDESCRIPTION")
+	for _, t := range list {
+		if !t.isPartial() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Language, t.Description)
+		}
+	}
+
+	fmt.Fprintln(w, "\nPARTIAL\tLANGUAGE\tDESCRIPTION")
+	for _, t := range list {
+		if t.isPartial() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", strings.TrimPrefix(t.Name, "partials/"), t.Language, t.Description)
+		}
+	}
+
+	fmt.Fprintln(w, "\nFUNCTION\tDESCRIPTION")
+	for _, f := range templateFuncDocs {
+		fmt.Fprintf(w, "%s\t%s\n", f.Usage, f.Description)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, "\nAll of the sprig functions (https://masterminds.github.io/sprig/) are available as well.")
+	return err
+}
+
+func newTemplatesShowCmd() *cobra.Command {
+	var language string
+
+	cmd := &cobra.Command{
+		Use:          "show <name>",
+		Short:        "Prints an embedded template or partial",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, data, err := findEmbeddedTemplate(args[0], language)
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(
+		&language,
+		"language",
+		"go",
+		"The language of the template (go|js).",
+	)
+
+	return cmd
+}
+
+func newTemplatesEjectCmd() *cobra.Command {
+	var (
+		language  string
+		overwrite bool
+	)
+
+	cmd := &cobra.Command{
+		Use:          "eject <name> <dir>",
+		Short:        "Writes an embedded template and the partials it references to a template directory",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := ejectTemplate(args[0], language, args[1], overwrite)
+			for _, v := range files {
+				fmt.Fprintln(cmd.OutOrStdout(), v)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(
+		&language,
+		"language",
+		"go",
+		"The language of the template (go|js).",
+	)
+
+	cmd.Flags().BoolVar(
+		&overwrite,
+		"overwrite",
+		false,
+		"When true, will overwrite existing files.",
+	)
+
+	return cmd
+}
+
+// ejectTemplate writes an embedded template and the partials it references to
+// dir, laid out to be used with --template-dir. It returns the files written.
+func ejectTemplate(name, language, dir string, overwrite bool) ([]string, error) {
+	t, data, err := findEmbeddedTemplate(name, language)
+	if err != nil {
+		return nil, err
+	}
+
+	toWrite := []embeddedTemplate{t}
+	partials, err := referencedPartials(data, language)
+	if err != nil {
+		return nil, fmt.Errorf("finding partials of %s: %w", t.file(), err)
+	}
+	toWrite = append(toWrite, partials...)
+
+	var written []string
+	for _, v := range toWrite {
+		data, err := templates.ReadFile(path.Join("templates", v.file()))
+		if err != nil {
+			return written, err
+		}
+
+		dest := filepath.Join(dir, filepath.FromSlash(v.file()))
+		if _, err := os.Stat(dest); err == nil && !overwrite {
+			return written, fmt.Errorf("%s already exists", dest)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return written, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return written, err
+		}
+		written = append(written, dest)
+	}
+
+	return written, nil
+}
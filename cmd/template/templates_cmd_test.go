@@ -0,0 +1,72 @@
+package template
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncDocs(t *testing.T) {
+	sprigFuncs := sprig.FuncMap()
+
+	var custom []string
+	for name := range templateFuncs("") {
+		if _, ok := sprigFuncs[name]; !ok {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+
+	var documented []string
+	for _, v := range templateFuncDocs {
+		documented = append(documented, v.Name)
+	}
+
+	require.Equal(t, custom, documented)
+}
+
+func TestListTemplates(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, listTemplates(&buf))
+
+	out := buf.String()
+	require.Regexp(t, `(?m)^http_server\s+go\s+The SVC interface and the HTTPServer routing requests to it\.$`, out)
+	require.Regexp(t, `(?m)^client\s+js\s+A JavaScript client for the API\.$`, out)
+	require.Regexp(t, `(?m)^param_int\s+go\s+Parses an integer path parameter of an operation\.$`, out)
+	require.Regexp(t, `(?m)^typename <string>\s+Camel cased name`, out)
+}
+
+func TestEjectTemplate(t *testing.T) {
+	dir := t.TempDir()
+
+	files, err := ejectTemplate("http_server", "go", dir, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "http_server.go.tmpl"),
+		filepath.Join(dir, "partials", "param_int.go.tmpl"),
+	}, files)
+
+	b, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	expected, err := templates.ReadFile("templates/http_server.go.tmpl")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(b))
+
+	_, err = ejectTemplate("http_server", "go", dir, false)
+	require.ErrorContains(t, err, "already exists")
+
+	_, err = ejectTemplate("http_server", "go", dir, true)
+	require.NoError(t, err)
+
+	files, err = ejectTemplate("client", "js", dir, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "client.js.tmpl")}, files)
+
+	_, err = ejectTemplate("nope", "go", dir, false)
+	require.ErrorContains(t, err, `template "nope" not found`)
+}
@@ -0,0 +1,147 @@
+package template
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// requestModelSuffix is appended to the name of a model to name its request
+// variant.
+const requestModelSuffix = "Create"
+
+// hasRequestVariant reports whether separate request and response models are
+// generated for a schema, which is the case when it has readOnly or writeOnly
+// properties, or properties whose schemas have them.
+func hasRequestVariant(s *base.Schema) bool {
+	return schemaHasVisibility(s, make(map[string]bool))
+}
+
+func schemaHasVisibility(s *base.Schema, seen map[string]bool) bool {
+	if s == nil {
+		return false
+	}
+
+	if s.Properties != nil {
+		for sp := range s.Properties.ValuesFromOldest() {
+			if proxyHasVisibility(sp, seen) {
+				return true
+			}
+		}
+	}
+
+	for _, sp := range slices.Concat(s.AllOf, s.AnyOf, s.OneOf) {
+		if schemaHasVisibility(sp.Schema(), seen) {
+			return true
+		}
+	}
+
+	if s.Items != nil && s.Items.IsA() {
+		return proxyHasVisibility(s.Items.A, seen)
+	}
+
+	return false
+}
+
+func proxyHasVisibility(sp *base.SchemaProxy, seen map[string]bool) bool {
+	if ref := sp.GetReference(); ref != "" {
+		// Schemas can reference themselves.
+		if seen[ref] {
+			return false
+		}
+		seen[ref] = true
+	}
+
+	s := sp.Schema()
+	if s == nil {
+		return false
+	}
+	if fromBoolPtr(s.ReadOnly) || fromBoolPtr(s.WriteOnly) {
+		return true
+	}
+	return schemaHasVisibility(s, seen)
+}
+
+// requestModelType returns the type of a schema in a request, when it's a
+// reference to a model with a request variant, or an array or nullable of one.
+// Otherwise it returns an empty string and the type is the same as in a
+// response.
+func requestModelType(sp *base.SchemaProxy) string {
+	if sp == nil {
+		return ""
+	}
+	s := sp.Schema()
+	if s == nil {
+		return ""
+	}
+
+	if ref := sp.GetReference(); ref != "" {
+		name, ok := strings.CutPrefix(ref, componentSchemaPrefix)
+		if !ok || !hasRequestVariant(s) {
+			return ""
+		}
+		return typeName(name) + requestModelSuffix
+	}
+
+	if slices.Contains(s.Type, "array") && s.Items != nil && s.Items.IsA() {
+		if t := requestModelType(s.Items.A); t != "" {
+			return "[]" + t
+		}
+		return ""
+	}
+
+	if len(s.Type) == 0 && len(s.AnyOf) == 2 {
+		for _, v := range s.AnyOf {
+			if t := requestModelType(v); t != "" {
+				return t
+			}
+		}
+	}
+
+	return ""
+}
+
+// visibilityVariants returns the models generated for a component schema. A
+// model with a request variant is generated without its writeOnly properties,
+// which are only ever sent, and the request variant without its readOnly
+// properties, which are only ever received.
+func visibilityVariants(m Model, s *base.Schema) []Model {
+	if m.Enumerated || !hasRequestVariant(s) {
+		return []Model{m}
+	}
+
+	resp := m
+	resp.Fields = nil
+	req := m
+	req.Name = m.Name + requestModelSuffix
+	req.Description = fmt.Sprintf("The request variant of %s, without its read only properties.", m.Name)
+	req.Fields = nil
+
+	for _, f := range m.Fields {
+		if !f.WriteOnly {
+			resp.Fields = append(resp.Fields, f)
+		}
+		if !f.ReadOnly {
+			if f.requestType != "" {
+				f.Type = f.requestType
+			}
+			req.Fields = append(req.Fields, f)
+		}
+	}
+
+	return []Model{resp, req}
+}
+
+// checkModelNames checks that no two models have the same name.
+func checkModelNames(models []Model) error {
+	seen := make(map[string]bool, len(models))
+	for _, m := range models {
+		if seen[m.Name] {
+			return fmt.Errorf("more than one model is named %s", m.Name)
+		}
+		seen[m.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	version "github.com/jasonhancock/cobra-version"
+	"github.com/jasonhancock/jasongen/internal/loader"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisibilityVariants(t *testing.T) {
+	tests := []struct {
+		desc    string
+		schemas string
+		models  []string
+		err     string
+	}{
+		{
+			"none",
+			`
+    Widget:
+      type: object
+      properties:
+        name:
+          type: string
+`,
+			[]string{"Widget"},
+			"",
+		},
+		{
+			"self reference",
+			`
+    Node:
+      type: object
+      properties:
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Node'
+        parent:
+          $ref: '#/components/schemas/Node'
+`,
+			[]string{"Node"},
+			"",
+		},
+		{
+			"self reference with read only",
+			`
+    Node:
+      type: object
+      properties:
+        id:
+          type: string
+          readOnly: true
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Node'
+`,
+			[]string{"Node", "NodeCreate"},
+			"",
+		},
+		{
+			"conflict",
+			`
+    Widget:
+      type: object
+      properties:
+        password:
+          type: string
+          writeOnly: true
+    WidgetCreate:
+      type: object
+      properties:
+        name:
+          type: string
+`,
+			nil,
+			"more than one model is named WidgetCreate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "openapi.yaml")
+			require.NoError(t, os.WriteFile(file, []byte("components:\n  schemas:"+tt.schemas), 0644))
+
+			doc, err := loader.MergeAndLoad("testdata/openapi_base.yaml", file)
+			require.NoError(t, err)
+
+			td, err := templateDataFrom(doc, "widgets", version.Info{}, cmdOptions{language: "go"})
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			var models []string
+			for _, m := range td.Models {
+				models = append(models, m.Name)
+			}
+			require.Equal(t, tt.models, models)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long to wait for the inputs to settle before
+// regenerating. Editors tend to write a file in several steps.
+const watchDebounce = 200 * time.Millisecond
+
+// watchedInputs are the files and directories a generated file is rendered
+// from.
+type watchedInputs struct {
+	// files are the absolute paths of the input files.
+	files []string
+
+	// trees are the absolute paths of directories every file below is an
+	// input.
+	trees []string
+}
+
+// watchInputs returns the inputs of a template run: the spec files, the filter
+// config, the template directories and the template itself when it's read from
+// a file.
+func watchInputs(tmpl string, opts cmdOptions, files ...string) (watchedInputs, error) {
+	var in watchedInputs
+	for _, v := range files {
+		abs, err := filepath.Abs(v)
+		if err != nil {
+			return in, err
+		}
+		in.files = append(in.files, abs)
+	}
+
+	if _, err := os.Stat(tmpl); err == nil {
+		abs, err := filepath.Abs(tmpl)
+		if err != nil {
+			return in, err
+		}
+		in.files = append(in.files, abs)
+	}
+
+	if opts.filter.config != "" {
+		abs, err := filepath.Abs(opts.filter.config)
+		if err != nil {
+			return in, err
+		}
+		in.files = append(in.files, abs)
+	}
+
+	for _, v := range opts.templateDirs {
+		abs, err := filepath.Abs(v)
+		if err != nil {
+			return in, err
+		}
+		in.trees = append(in.trees, abs)
+	}
+
+	return in, nil
+}
+
+// dirs returns the directories to watch. Directories are watched instead of
+// the files themselves because editors often replace a file when saving it.
+func (in watchedInputs) dirs() ([]string, error) {
+	var dirs []string
+	add := func(dir string) {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, v := range in.files {
+		add(filepath.Dir(v))
+	}
+
+	for _, root := range in.trees {
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				add(p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("template dir: %w", err)
+		}
+	}
+
+	return dirs, nil
+}
+
+// contains reports whether a file is one of the inputs.
+func (in watchedInputs) contains(file string) bool {
+	if slices.Contains(in.files, file) {
+		return true
+	}
+	for _, root := range in.trees {
+		if strings.HasPrefix(file, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchTemplate runs generate and then runs it again whenever one of the inputs
+// changes, until the context is done. Errors are written to out instead of
+// stopping the watch.
+func watchTemplate(ctx context.Context, out io.Writer, debounce time.Duration, in watchedInputs, generate func() error) error {
+	dirs, err := in.dirs()
+	if err != nil {
+		return err
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer w.Close()
+
+	for _, v := range dirs {
+		if err := w.Add(v); err != nil {
+			return fmt.Errorf("watching %s: %w", v, err)
+		}
+	}
+
+	run := func() {
+		if err := generate(); err != nil {
+			fmt.Fprintf(out, "ERROR: %s\n", err)
+			return
+		}
+		fmt.Fprintf(out, "%s generated\n", time.Now().Format(time.TimeOnly))
+	}
+
+	run()
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) || !in.contains(event.Name) {
+				continue
+			}
+			timer.Reset(debounce)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(out, "ERROR: watching: %s\n", err)
+		case <-timer.C:
+			run()
+		}
+	}
+}
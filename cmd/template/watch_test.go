@@ -0,0 +1,128 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchInputs(t *testing.T) {
+	dir := t.TempDir()
+	tmplDir := filepath.Join(dir, "templates")
+	require.NoError(t, os.MkdirAll(filepath.Join(tmplDir, "partials"), 0755))
+
+	spec := filepath.Join(dir, "specs", "openapi.yaml")
+	filterConfig := filepath.Join(dir, "specs", "filter.yaml")
+
+	in, err := watchInputs("service", cmdOptions{templateDirs: []string{tmplDir}, filter: operationFilter{config: filterConfig}}, spec)
+	require.NoError(t, err)
+
+	dirs, err := in.dirs()
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "specs"), tmplDir, filepath.Join(tmplDir, "partials")}, dirs)
+
+	tests := []struct {
+		file     string
+		expected bool
+	}{
+		{spec, true},
+		{filterConfig, true},
+		{filepath.Join(dir, "specs", "other.yaml"), false},
+		{filepath.Join(tmplDir, "service.go.tmpl"), true},
+		{filepath.Join(tmplDir, "partials", "param_int.go.tmpl"), true},
+		{tmplDir + "2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			require.Equal(t, tt.expected, in.contains(tt.file))
+		})
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchTemplate(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte("v1"), 0644))
+
+	in, err := watchInputs("service", cmdOptions{}, spec)
+	require.NoError(t, err)
+
+	var (
+		mu   sync.Mutex
+		runs []string
+	)
+	generate := func() error {
+		b, err := os.ReadFile(spec)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		runs = append(runs, string(b))
+		if string(b) == "broken" {
+			return os.ErrInvalid
+		}
+		return nil
+	}
+	generated := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), runs...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error)
+	go func() {
+		done <- watchTemplate(ctx, &out, 50*time.Millisecond, in, generate)
+	}()
+
+	require.Eventually(t, func() bool { return len(generated()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// Changes to other files in the directory are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("x"), 0644))
+
+	// Errors are reported and the watch keeps going.
+	require.NoError(t, os.WriteFile(spec, []byte("broken"), 0644))
+	require.Eventually(t, func() bool { return len(generated()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ERROR: ") }, 5*time.Second, 10*time.Millisecond)
+
+	// Editors replace files when saving them.
+	tmp := filepath.Join(dir, "openapi.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("v2"), 0644))
+	require.NoError(t, os.Rename(tmp, spec))
+	require.Eventually(t, func() bool {
+		runs := generated()
+		return runs[len(runs)-1] == "v2"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"v1", "broken", "v2"}, generated())
+}
@@ -0,0 +1,78 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jasonhancock/go-helpers"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Webhook is a webhook defined in the webhooks section of the document.
+type Webhook struct {
+	// Name is used to derive the Go identifiers. It is the operationId of the
+	// webhook, falling back to the key of the webhook in the document.
+	Name string
+
+	// Event is the key of the webhook in the document. It is sent along with
+	// the payload so that the receiver can dispatch it.
+	Event string
+
+	Method      string
+	PayloadType string
+	op          *v3high.Operation
+	unions      []*UnionModelType
+}
+
+func (w Webhook) ExportedName() string {
+	return typeName(w.Name)
+}
+
+func (w Webhook) Description() string {
+	return strings.TrimSpace(strings.ReplaceAll(w.op.Description, "\n", " "))
+}
+
+func (w Webhook) Comment() string {
+	return helpers.LCFirst(w.Description())
+}
+
+func getWebhooks(doc *v3high.Document) ([]Webhook, error) {
+	if doc.Webhooks == nil {
+		return nil, nil
+	}
+
+	var webhooks []Webhook
+	for pair := doc.Webhooks.First(); pair != nil; pair = pair.Next() {
+		event := pair.Key()
+		for opPair := pair.Value().GetOperations().First(); opPair != nil; opPair = opPair.Next() {
+			op := opPair.Value()
+
+			w := Webhook{
+				Name:   op.OperationId,
+				Event:  event,
+				Method: opPair.Key(),
+				op:     op,
+			}
+			if w.Name == "" {
+				w.Name = event
+			}
+
+			var err error
+			w.PayloadType, err = getRequestBodyType(op)
+			if err != nil {
+				return nil, fmt.Errorf("getting payload type %s: %w", w.Name, err)
+			}
+			if w.PayloadType == "" {
+				return nil, fmt.Errorf("webhook %s has no application/json request body", w.Name)
+			}
+			w.unions, err = operationUnions(op)
+			if err != nil {
+				return nil, fmt.Errorf("getting unions %s: %w", w.Name, err)
+			}
+
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	return webhooks, nil
+}
@@ -5,12 +5,14 @@ go 1.25.0
 require (
 	github.com/Masterminds/sprig/v3 v3.3.0
 	github.com/TwiN/deepmerge v0.2.2
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/jasonhancock/cobra-version v0.0.5
 	github.com/jasonhancock/cobraflags/root v0.0.11
 	github.com/jasonhancock/go-helpers v0.0.11
 	github.com/jasonhancock/go-testhelpers/generic v0.0.16
 	github.com/kenshaw/snaker v0.4.3
 	github.com/pb33f/libopenapi v0.29.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.10.2
 	github.com/stretchr/testify v1.11.1
 	github.com/stuart-warren/yamlfmt v0.2.0
@@ -38,7 +40,6 @@ require (
 	github.com/mitchellh/reflectwalk v1.0.2 // indirect
 	github.com/pb33f/jsonpath v0.7.0 // indirect
 	github.com/pb33f/ordered-map/v2 v2.3.0 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/shopspring/decimal v1.4.0 // indirect
 	github.com/spf13/cast v1.7.0 // indirect
@@ -47,5 +48,6 @@ require (
 	golang.org/x/crypto v0.46.0 // indirect
 	golang.org/x/mod v0.34.0 // indirect
 	golang.org/x/sync v0.20.0 // indirect
+	golang.org/x/sys v0.42.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
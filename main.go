@@ -31,6 +31,8 @@ func main() {
 
 	r.AddCommand(
 		template.NewCmd(r),
+		template.NewTemplatesCmd(),
+		template.NewStaleCmd(),
 	)
 
 	r.Execute()